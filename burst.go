@@ -1,260 +1,1345 @@
 package gotree
 
-// TODO: accept empty strings for search, insertion and removal
-
 import (
-	//"container/list"
+	"bytes"
+	"container/list"
 	"fmt"
 	"github.com/davecgh/go-spew/spew"
+	"iter"
+	"math"
+	"math/rand"
 	"runtime"
+	"slices"
 )
 
 var _ = spew.Dump
 var _ = fmt.Println
-var containerMax int
 
-func init() {
-	containerMax = listContainerMax
+// doesn't follow container interface for it's not a container but a "trie node"
+type accessContainer[T any] struct {
+	// bytes shared by every key at and below, following the record leading here. So a long run
+	// of shared bytes takes one access container rather than a chain of them. Always empty for the root.
+	segment   []byte
+	single    T             // empty string case, the key ending with the segment
+	hasSingle bool          // whether single holds an item
+	records   accessRecords // each may be a accessContainer or container
 }
 
-// doesn't follow container interface for it's not a container but a "trie node"
-type accessContainer struct {
-	single  Byte             // empty string case
-	records [256]interface{} // may be a accessContainer or container
+// split divides the segment after its first j bytes, returning the new access container
+// to take the place of a, which goes below it holding the rest of the segment.
+func (a *accessContainer[T]) split(j int) *accessContainer[T] {
+	top := &accessContainer[T]{segment: a.segment[:j:j]}
+	top.records.set(a.segment[j], a)
+	a.segment = a.segment[j+1:]
+	return top
 }
 
-type BurstTree struct {
+// merge returns the access container to take the place of a, which is a itself unless it
+// holds no item and only one record, an access container. Then that takes a's place, its
+// segment lengthened to cover a.
+func (a *accessContainer[T]) merge() *accessContainer[T] {
+	if a.hasSingle || a.records.len() != 1 {
+		return a
+	}
+	via := a.records.next(0)
+	only, ok := a.records.get(byte(via)).(*accessContainer[T])
+	if !ok {
+		return a
+	}
+	segment := make([]byte, 0, len(a.segment)+1+len(only.segment))
+	segment = append(append(append(segment, a.segment...), byte(via)), only.segment...)
+	only.segment = segment
+	return only
+}
+
+// isEmpty reports whether a holds nothing at all.
+func (a *accessContainer[T]) isEmpty() bool {
+	return !a.hasSingle && a.records.len() == 0
+}
+
+// A BurstTreeOf is the type parameterized burst tree, elements are stored under the key given by its KeyFunc.
+// The zero value uses the elements own ToBytes method, and so is only usable for types implementing Byte.
+type BurstTreeOf[T any] struct {
 	root     interface{}
 	size     int
-	iterNext func() Byte
+	iterNext func() (T, bool)
+	iterKey  []byte // key of the element last returned by IterInit or Next
+	key      KeyFunc[T]
+	rand     *rand.Rand // source for RandOrder, nil for the global source
+	// makes the leaves, nil for compactArray
+	newContainer func() container[T]
+	config       BurstConfig // the zero value being the default
+	bursts       int         // containers burst since made or cleared
+	merges       int         // subtries merged since made or cleared
+}
+
+// NewBurstTreeOf returns an empty tree which stores its elements under the keys given by key,
+// configured by any options given.
+func NewBurstTreeOf[T any](key KeyFunc[T], opts ...BurstOption) *BurstTreeOf[T] {
+	burst := &BurstTreeOf[T]{key: key}
+	burst.configure(opts)
+	return burst
+}
+
+// A BurstTree is a thin wrapper around BurstTreeOf which uses nil to signal a missing element.
+type BurstTree struct {
+	BurstTreeOf[Byte]
+}
+
+// NewBurstTree returns an empty tree configured by any options given. The zero value of a
+// BurstTree is also ready for use, with the default configuration.
+func NewBurstTree(opts ...BurstOption) *BurstTree {
+	burst := &BurstTree{}
+	burst.configure(opts)
+	return burst
+}
+
+// ContainerKind names one of the leaf containers built into BurstTree.
+type ContainerKind int
+
+const (
+	CompactArray  ContainerKind = iota // length prefixed suffixes packed into a single slice, the default
+	ListContainer                      // a linked list, kept in most recently used order
+	ArrayHash                          // a hash table of compact arrays, making the tree a HAT-trie
+	SortedArray                        // a compact array kept in order, and binary searched
+)
+
+// human readable representation of ContainerKind values
+// used for debug and error reporting
+func (k ContainerKind) String() string {
+	var s string
+	switch k {
+	case CompactArray:
+		s = "compact array"
+	case ListContainer:
+		s = "list container"
+	case ArrayHash:
+		s = "array hash"
+	case SortedArray:
+		s = "sorted array"
+	default:
+		s = fmt.Sprintf("ContainerKind(%d)", int(k))
+	}
+	return s
+}
+
+// the burst limit of each kind of container, unless told otherwise
+func (k ContainerKind) defaultLimit() int {
+	switch k {
+	case ListContainer:
+		return 150
+	case ArrayHash:
+		// its searches don't slow down as quickly as it grows
+		return 1024
+	}
+	// a SortedArray searches quickly at any size, but each insertion moves half of it,
+	// so it does best bursting no later than a CompactArray, see BenchmarkBurstSortedLimits
+	return 256
+}
+
+// BurstPolicy decides when a container has grown too big for its own good, and is to be burst.
+type BurstPolicy int
+
+const (
+	// CountPolicy, the default, bursts a container once it holds more than BurstLimit items.
+	CountPolicy BurstPolicy = iota
+	// SizePolicy bursts a container once its suffixes take more than ByteLimit bytes,
+	// so containers of long suffixes burst sooner.
+	SizePolicy
+	// RatioPolicy bursts a container which is hot and mostly misses, that is once it has been
+	// accessed MinAccesses times and more than MissRatio of those searches and insertions were
+	// for a suffix it didn't hold. Containers which mostly find what they're asked for are left
+	// alone whatever their size. Counting accesses makes Search write to the tree,
+	// so even searches are then unsafe to run concurrently.
+	RatioPolicy
+)
+
+// human readable representation of BurstPolicy values
+// used for debug and error reporting
+func (p BurstPolicy) String() string {
+	var s string
+	switch p {
+	case CountPolicy:
+		s = "count policy"
+	case SizePolicy:
+		s = "size policy"
+	case RatioPolicy:
+		s = "ratio policy"
+	default:
+		s = fmt.Sprintf("BurstPolicy(%d)", int(p))
+	}
+	return s
+}
+
+// BurstConfig is the configuration of a BurstTree, see WithConfig. Any zero field
+// is given its default.
+type BurstConfig struct {
+	Container ContainerKind // the kind of leaf container
+	Policy    BurstPolicy   // when to burst a container
+	// BurstLimit is the number of items a container may hold before it bursts under
+	// CountPolicy, the default depending upon the kind of Container.
+	BurstLimit int
+	// ByteLimit is the number of bytes of suffixes a container may hold before it bursts
+	// under SizePolicy, 4096 by default.
+	ByteLimit int
+	// MinAccesses and MissRatio are for RatioPolicy, by default 32 and 0.5.
+	MinAccesses int
+	MissRatio   float64
+	// MergeLimit is the number of items at or below which Remove merges a subtrie back into a
+	// single container, counted in bytes of suffixes under SizePolicy. By default it is a quarter
+	// of BurstLimit, or of ByteLimit, which leaves room for the container to grow before bursting again.
+	// RatioPolicy bursts by misses rather than size, so any size of container might burst again,
+	// and by default only a subtrie left with a lone item is merged, for that can't burst.
+	// A negative MergeLimit has Remove only drop empty access containers, leaving the rest to Compact.
+	MergeLimit int
+}
+
+// the configuration with its defaults filled in
+func (c BurstConfig) resolve() BurstConfig {
+	if c.BurstLimit <= 0 {
+		c.BurstLimit = c.Container.defaultLimit()
+	}
+	if c.ByteLimit <= 0 {
+		c.ByteLimit = 4096
+	}
+	if c.MinAccesses <= 0 {
+		c.MinAccesses = 32
+	}
+	if c.MissRatio <= 0 {
+		c.MissRatio = 0.5
+	}
+	if c.MergeLimit == 0 {
+		c.MergeLimit = c.defaultMerge()
+	}
+	return c
+}
+
+// the default MergeLimit
+func (c BurstConfig) defaultMerge() int {
+	switch c.Policy {
+	case SizePolicy:
+		return c.ByteLimit / 4
+	case RatioPolicy:
+		return 1
+	}
+	return c.BurstLimit / 4
+}
+
+// a configuration under which containers never burst
+var neverBurst = &BurstConfig{BurstLimit: math.MaxInt}
+
+// full reports whether the policy would have c burst.
+func (p *BurstConfig) full(c burstable) bool {
+	switch p.Policy {
+	case SizePolicy:
+		return c.size() > 1 && c.footprint() > p.ByteLimit
+	case RatioPolicy:
+		s := c.counts()
+		return c.size() > 1 && s.accesses >= p.MinAccesses && float64(s.misses) > p.MissRatio*float64(s.accesses)
+	}
+	return c.size() > p.BurstLimit
+}
+
+// A BurstOption configures a BurstTree as it is made by NewBurstTree or NewBurstTreeOf.
+type BurstOption func(*burstConfig)
+
+type burstConfig struct {
+	BurstConfig
+	custom interface{} // the func() Container[T] given to WithContainer, if any
 }
 
-func (burst *BurstTree) Clear() {
+// WithConfig sets the whole configuration of the tree at once, as returned by Tune for instance.
+func WithConfig(config BurstConfig) BurstOption {
+	return func(c *burstConfig) {
+		c.BurstConfig, c.custom = config, nil
+	}
+}
+
+// WithContainerKind has the tree build its leaves from one of the built in containers.
+func WithContainerKind(kind ContainerKind) BurstOption {
+	return func(c *burstConfig) {
+		c.Container, c.custom = kind, nil
+	}
+}
+
+// WithBurstLimit sets the number of items a container may hold before it bursts.
+// The smaller it is, the deeper and faster, but bigger, the tree.
+func WithBurstLimit(limit int) BurstOption {
+	return func(c *burstConfig) {
+		c.BurstLimit = limit
+	}
+}
+
+// WithPolicy chooses when containers are burst, see BurstPolicy.
+func WithPolicy(policy BurstPolicy) BurstOption {
+	return func(c *burstConfig) {
+		c.Policy = policy
+	}
+}
+
+// WithContainer has the tree build its leaves by calling newContainer, each one being empty.
+// It's for trees of elements of type T only, any other tree will panic when made with it.
+// Unless a limit is also given, these burst at the default limit of a compact array.
+func WithContainer[T any](newContainer func() Container[T]) BurstOption {
+	return func(c *burstConfig) {
+		c.Container, c.custom = CompactArray, newContainer
+	}
+}
+
+func (burst *BurstTreeOf[T]) configure(opts []BurstOption) {
+	var config burstConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	burst.config = config.resolve()
+	switch config.Policy {
+	case CountPolicy, SizePolicy, RatioPolicy:
+	default:
+		s := fmt.Sprintf("BurstTree has not implemented %s.", config.Policy)
+		panic(s)
+	}
+	if config.custom != nil {
+		newCustom, ok := config.custom.(func() Container[T])
+		if !ok {
+			s := fmt.Sprintf("BurstTree of %T given containers made by %T.", *new(T), config.custom)
+			panic(s)
+		}
+		burst.newContainer = func() container[T] {
+			return &customContainer[T]{Container: newCustom(), newContainer: newCustom}
+		}
+		return
+	}
+	burst.newContainer = containerMaker[T](config.Container)
+}
+
+// containerMaker returns a function making empty containers of the given kind.
+func containerMaker[T any](kind ContainerKind) func() container[T] {
+	switch kind {
+	case CompactArray:
+		return func() container[T] {
+			return &compactArray[T]{}
+		}
+	case ListContainer:
+		return func() container[T] {
+			return &listContainer[T]{List: list.New()}
+		}
+	case ArrayHash:
+		return func() container[T] {
+			return &arrayHash[T]{}
+		}
+	case SortedArray:
+		return func() container[T] {
+			return &sortedArray[T]{}
+		}
+	}
+	s := fmt.Sprintf("BurstTree has not implemented %s.", kind)
+	panic(s)
+}
+
+// Config returns the configuration of the tree, with any defaults filled in.
+func (burst *BurstTreeOf[T]) Config() BurstConfig {
+	// resolving again leaves the tree untouched, so Config may be called alongside Search
+	return burst.config.resolve()
+}
+
+// policy returns the resolved configuration, filling in the defaults of a zero value tree.
+// It stores them, so is only for the methods which write to the tree.
+func (burst *BurstTreeOf[T]) policy() *BurstConfig {
+	if burst.config.BurstLimit == 0 {
+		burst.config = burst.config.resolve()
+	}
+	return &burst.config
+}
+
+// BurstStats describes the shape a BurstTree has grown into, for comparing configurations
+// upon a workload.
+type BurstStats struct {
+	Items          int // elements held
+	AccessNodes    int // access containers, the inner nodes of the trie
+	AccessBytes    int // bytes taken by the records and segments of the access containers
+	Containers     int // leaf containers
+	ContainerBytes int // bytes taken by the suffixes held within the containers
+	MaxDepth       int // access containers on the longest path down from the root
+	Bursts         int // containers burst since the tree was made or cleared
+	Merges         int // subtries merged back into one container since the tree was made or cleared
+}
+
+// MeanContainer is the average number of items held by a container.
+func (s BurstStats) MeanContainer() float64 {
+	if s.Containers == 0 {
+		return 0
+	}
+	return float64(s.Items) / float64(s.Containers)
+}
+
+// Stats walks the tree to describe its shape.
+func (burst *BurstTreeOf[T]) Stats() BurstStats {
+	stats := BurstStats{Items: burst.size, Bursts: burst.bursts, Merges: burst.merges}
+	var walk func(a *accessContainer[T], depth int)
+	walk = func(a *accessContainer[T], depth int) {
+		stats.AccessNodes++
+		stats.AccessBytes += a.records.footprint() + len(a.segment)
+		if depth > stats.MaxDepth {
+			stats.MaxDepth = depth
+		}
+		for _, record := range a.records.all() {
+			switch cur := record.(type) {
+			case *accessContainer[T]:
+				walk(cur, depth+1)
+			case container[T]:
+				stats.Containers++
+				stats.ContainerBytes += cur.footprint()
+			}
+		}
+	}
+	if root, ok := burst.root.(*accessContainer[T]); ok {
+		walk(root, 1)
+	}
+	return stats
+}
+
+// makeContainer returns a new empty leaf of the kind the tree was configured with.
+func (burst *BurstTreeOf[T]) makeContainer() container[T] {
+	if burst.newContainer == nil {
+		return &compactArray[T]{}
+	}
+	return burst.newContainer()
+}
+
+// toBytes gives the key of item, or nil when item is a nil interface and so has no key.
+// Any other nil, be it from a KeyFunc or a Byte's ToBytes, is the empty key.
+func (burst *BurstTreeOf[T]) toBytes(item T) []byte {
+	var key []byte
+	switch {
+	case burst.key != nil:
+		key = burst.key(item)
+	case any(item) == nil:
+		return nil
+	default:
+		key = keyByte(item)
+	}
+	if key == nil {
+		return []byte{}
+	}
+	return key
+}
+
+func (burst *BurstTreeOf[T]) Clear() {
 	burst.root = nil
 	burst.size = 0
 	burst.iterNext = nil
+	burst.iterKey = nil
+	burst.bursts = 0
+	burst.merges = 0
 	runtime.GC()
 }
 
-func (burst *BurstTree) Search(item Byte) (found Byte) {
+func (burst *BurstTreeOf[T]) Search(item T) (found T, ok bool) {
 
 	// preconditions
-	if item == nil || burst.root == nil {
+	if burst.root == nil {
 		return
 	}
-	query := item.ToBytes()
+	query := burst.toBytes(item)
 	if query == nil {
-		// bad method call from Byte type
+		// a nil item has no key
 		return
 	}
 	n := len(query)
-	if n == 0 {
-		// don't accept empty case for first access container
-		return
-	}
 
-	c := burst.root                // interface
-	parent := c.(*accessContainer) // must always be non nil
+	c := burst.root // interface
 	for i := 0; ; i++ {
 		switch cOld := c.(type) {
-		case *accessContainer:
+		case *accessContainer[T]:
+			if !bytes.HasPrefix(query[i:], cOld.segment) {
+				// query leaves the segment, so isn't below
+				return
+			}
+			i += len(cOld.segment)
 			// empty string case
 			if i == n {
-				return cOld.single
+				return cOld.single, cOld.hasSingle
 			}
-			parent = cOld
 			// use our current byte as index to next level of trie
-			c = cOld.records[query[i]]
-		case container:
+			c = cOld.records.get(query[i])
+		case container[T]:
 			suffix := query[i:]
 			// needs to handle suffix being an empty string case!!
-			return cOld.search(suffix)
+			found, ok = cOld.search(suffix)
+			if burst.config.Policy == RatioPolicy {
+				cOld.counts().record(!ok)
+			}
+			return
 		case nil:
-			// only possible place is last access container
-			return parent.single
+			// nothing stored below this prefix
+			return
 		}
 	}
 }
 
-func (burst *BurstTree) Insert(item Byte) (old Byte) {
-
-	// preconditions
-	if item == nil {
-		return
+// LongestPrefix returns the element whose key is the longest prefix of query, the key
+// equal to query included. If no stored key is a prefix of query, then ok is false.
+func (burst *BurstTreeOf[T]) LongestPrefix(query []byte) (found T, ok bool) {
+	c := burst.root // interface
+	for i := 0; ; i++ {
+		switch cOld := c.(type) {
+		case *accessContainer[T]:
+			if !bytes.HasPrefix(query[i:], cOld.segment) {
+				// no key further down is a prefix of query
+				return
+			}
+			i += len(cOld.segment)
+			// a key ending here is the best so far
+			if cOld.hasSingle {
+				found, ok = cOld.single, true
+			}
+			if i == len(query) {
+				return
+			}
+			// use our current byte as index to next level of trie
+			c = cOld.records.get(query[i])
+		case container[T]:
+			if cFound, cOk := cOld.longestPrefix(query[i:]); cOk {
+				found, ok = cFound, cOk
+			}
+			return
+		case nil:
+			// nothing stored below this prefix
+			return
+		}
 	}
-	query := item.ToBytes()
+}
+
+func (burst *BurstTreeOf[T]) Insert(item T) (old T, ok bool) {
 
+	query := burst.toBytes(item)
+
+	// preconditions
 	if query == nil {
 		return
 	}
 
 	// need a non nil parent for traversal
 	if burst.root == nil {
-		burst.root = &accessContainer{}
+		burst.root = &accessContainer[T]{}
 	}
 
 	n := len(query)
-	if n == 0 {
-		return
-	}
 
 	c := burst.root
 
 	// We need the parent for we may burst and add an
 	// access tree which needs to be linked with it's proper parent.
-	parent := burst.root.(*accessContainer)
+	parent := burst.root.(*accessContainer[T])
 
 	for i := 0; ; i++ {
 		switch cOld := c.(type) {
-		case *accessContainer:
+		case *accessContainer[T]:
+			// split the segment where query leaves it, the root never has one
+			if j := commonPrefix(cOld.segment, query[i:]); j < len(cOld.segment) {
+				cOld = cOld.split(j)
+				parent.records.set(query[i-1], cOld)
+			}
+			i += len(cOld.segment)
 
 			// empty string case
 			if i == n {
-				old = cOld.single
-				cOld.single = item
-				if old == nil {
+				old, ok = cOld.single, cOld.hasSingle
+				cOld.single, cOld.hasSingle = item, true
+				if !ok {
 					burst.size++
 				}
 				return
 			}
 			parent = cOld
-			c = cOld.records[query[i]]
-		case container:
+			c = cOld.records.get(query[i])
+		case container[T]:
 			suffix := query[i:]
-			found, newParent := cOld.insert(suffix, item)
+			p := burst.policy()
+			if p.Policy == RatioPolicy {
+				_, held := cOld.search(suffix)
+				cOld.counts().record(!held)
+			}
+			old, ok, newParent := cOld.insert(suffix, item, p)
 			if newParent != nil {
-				parent.records[query[i-1]] = newParent
+				parent.records.set(query[i-1], newParent)
+				burst.bursts++
 			}
-			if found == nil {
+			if !ok {
 				burst.size++
 			}
-			return found
+			return old, ok
 		case nil:
-			var newContainer container
 			suffix := query[i:]
-			newContainer = &compactArray{}
-			//newContainer := &listContainer{list.New(), nil} // TODO: Try other concrete types of containers
-			old, _ /*Should never burst,or else it's just a simple trie */ = newContainer.insert(suffix, item)
-			parent.records[query[i-1]] = newContainer
+			newContainer := burst.makeContainer()
+			old, ok, _ /*Should never burst,or else it's just a simple trie */ = newContainer.insert(suffix, item, burst.policy())
+			parent.records.set(query[i-1], newContainer)
 			burst.size++
 			return
 		}
 	}
 }
 
-func (burst *BurstTree) Size() int {
+func (burst *BurstTreeOf[T]) Size() int {
 	return burst.size
 }
 
-func (burst *BurstTree) Remove(item Byte) (old Byte) {
+func (burst *BurstTreeOf[T]) Remove(item T) (old T, ok bool) {
 
 	// preconditions
-	if item == nil || burst.root == nil {
+	if burst.root == nil {
 		return
 	}
-	query := item.ToBytes()
+	query := burst.toBytes(item)
 	if query == nil {
 		return
 	}
 	n := len(query)
-	if n == 0 {
-		return
-	}
 
 	c := burst.root // current object
-	// we need the parents for we may fully empty access containers which may trigger more removes in prior depths
-	parents := []*accessContainer{}
-	parent := burst.root.(*accessContainer)
+	// we need the parents for we may fully empty access containers which may trigger more removes in prior depths,
+	// along with the byte of the record each one was left by
+	parents := []*accessContainer[T]{}
+	via := []byte{}
 	for i := 0; ; i++ {
 		switch cOld := c.(type) {
-		case *accessContainer:
+		case *accessContainer[T]:
+			if !bytes.HasPrefix(query[i:], cOld.segment) {
+				return // found nothing
+			}
+			i += len(cOld.segment)
+			parents = append(parents, cOld)
 			// empty string case
 			if i == n {
-				old = cOld.single
-				if old != nil {
+				old, ok = cOld.single, cOld.hasSingle
+				if ok {
 					burst.size--
-					cOld.single = nil
+					var zero T
+					cOld.single, cOld.hasSingle = zero, false
 					goto CheckEmpty
 				}
 				return // found nothing
 			}
-			parent = cOld
-			parents = append(parents, cOld)
-			c = cOld.records[query[i]]
-		case container:
+			via = append(via, query[i])
+			c = cOld.records.get(query[i])
+		case container[T]:
 			suffix := query[i:]
-			old = cOld.remove(suffix)
-			if old != nil {
+			old, ok = cOld.remove(suffix)
+			if ok {
 				burst.size--
 				if cOld.isEmpty() {
 					// remove empty container
-					parent.records[query[i-1]] = nil
+					parents[len(parents)-1].records.set(query[i-1], nil)
 				}
 				goto CheckEmpty
 			}
 			return // found nothing
 
 		case nil:
-			// if present only possible place is last access container
-			old = parent.single
-			if old != nil {
-				burst.size--
-				parent.single = nil
-				goto CheckEmpty
-			}
 			return // found nothing
 
 		}
 	}
-	panic("Shouldn't be here")
 
 CheckEmpty:
 	// continually check for empty access containers
-	for last := len(parents) - 1; last > 0; last-- {
-		parent := parents[last]
-		if parent.single != nil { // not empty, stop
+	last := len(parents) - 1
+	for ; last > 0 && parents[last].isEmpty(); last-- {
+		parents[last-1].records.set(via[last-1], nil)
+	}
+	if last == 0 {
+		if parents[0].isEmpty() {
+			// nothing left at all
+			burst.root = nil
+		}
+		return
+	}
+	limit := burst.policy().MergeLimit
+	if limit <= 0 {
+		// merging is left to Compact
+		return
+	}
+	// then for subtries few enough to go back into a container, each one making its parent smaller
+	for ; last > 0 && burst.underfull(parents[last], limit); last-- {
+		parents[last-1].records.set(via[last-1], burst.unburst(parents[last]))
+	}
+	// what is left may now be merged with its only child
+	if last > 0 {
+		parents[last-1].records.set(via[last-1], parents[last].merge())
+	}
+	return
+}
+
+// Compact tidies the tree, merging every subtrie which has become small enough back into a single container,
+// and any access container left with a lone access container below into that one. Remove does the same along
+// the path of each key it removes, unless the MergeLimit is negative, in which case it is left to Compact.
+// Then the merge limit is the default.
+func (burst *BurstTreeOf[T]) Compact() {
+	root, ok := burst.root.(*accessContainer[T])
+	if !ok {
+		return
+	}
+	p := burst.policy()
+	limit := p.MergeLimit
+	if limit < 0 {
+		limit = p.defaultMerge()
+	}
+	var compact func(a *accessContainer[T]) interface{}
+	compact = func(a *accessContainer[T]) interface{} {
+		// from the bottom up, so a parent sees its merged children
+		for i, record := range a.records.all() {
+			if cur, ok := record.(*accessContainer[T]); ok {
+				a.records.set(byte(i), compact(cur))
+			}
+		}
+		if a == root {
+			return a
+		}
+		if limit > 0 && burst.underfull(a, limit) {
+			return burst.unburst(a)
+		}
+		return a.merge()
+	}
+	compact(root)
+}
+
+// underfull reports whether the subtrie at a holds no more than limit items, or under SizePolicy
+// whether the container they would be merged into holds no more than limit bytes of suffixes,
+// and so could be held by a single container.
+func (burst *BurstTreeOf[T]) underfull(a *accessContainer[T], limit int) bool {
+	bySize := burst.policy().Policy == SizePolicy
+	// what an item takes in the merged container, where its suffix is d bytes long
+	weigh := func(d int) int {
+		switch {
+		case !bySize:
+			return 1
+		case d == 0:
+			// the empty suffix is held aside from the others
+			return 0
+		}
+		return lenWidth(d) + d
+	}
+	n := 0
+	// false once over the limit, so no more of the subtrie need be seen, depth being
+	// the length of the suffixes down to a
+	var count func(a *accessContainer[T], depth int) bool
+	count = func(a *accessContainer[T], depth int) bool {
+		depth += len(a.segment)
+		if a.hasSingle {
+			n += weigh(depth)
+		}
+		for _, record := range a.records.all() {
+			switch cur := record.(type) {
+			case *accessContainer[T]:
+				if !count(cur, depth+1) {
+					return false
+				}
+			case container[T]:
+				if !bySize {
+					// size leaves out the empty suffix
+					n += cur.size()
+					if _, ok := cur.search(nil); ok {
+						n++
+					}
+					break
+				}
+				next := cur.iter(AnyOrder)
+				for suffix, _, ok := next(); ok && n <= limit; suffix, _, ok = next() {
+					n += weigh(depth + 1 + len(suffix))
+				}
+			}
+			if n > limit {
+				return false
+			}
+		}
+		return n <= limit
+	}
+	return count(a, 0)
+}
+
+// unburst gathers the items of the subtrie at a into a new container, to take a's place.
+func (burst *BurstTreeOf[T]) unburst(a *accessContainer[T]) container[T] {
+	c := burst.makeContainer()
+	a.all(InOrder, nil, func(key []byte, item T) bool {
+		c.insert(key, item, neverBurst)
+		return true
+	})
+	burst.merges++
+	return c
+}
+
+// a level of a burstCursor's path, index being the record we have descended into,
+// or -1 when at the access containers single item.
+type burstLevel[T any] struct {
+	node  *accessContainer[T]
+	index int
+}
+
+// burstCursor is the CursorOf a BurstTreeOf, it holds the path of access containers from the
+// root down to its current position. Once within a container, it walks a sorted copy of the
+// containers entries.
+type burstCursor[T any] struct {
+	tree    *BurstTreeOf[T]
+	path    []burstLevel[T] // empty when not positioned
+	entries []containerEntry[T]
+	pos     int // position in entries, if within a container
+}
+
+// Cursor returns a new unpositioned cursor over the tree. Elements are visited in the
+// lexicographic order of their keys. See CursorOf for an example.
+func (burst *BurstTreeOf[T]) Cursor() CursorOf[T] {
+	return &burstCursor[T]{tree: burst}
+}
+
+func (c *burstCursor[T]) Elem() (elem T, ok bool) {
+	if len(c.path) == 0 {
+		return
+	}
+	if c.entries != nil {
+		return c.entries[c.pos].item, true
+	}
+	level := c.path[len(c.path)-1]
+	return level.node.single, true
+}
+
+func (c *burstCursor[T]) Next() bool {
+	if c.entries != nil && c.pos+1 < len(c.entries) {
+		c.pos++
+		return true
+	}
+	c.entries = nil
+	if len(c.path) == 0 {
+		root, _ := c.tree.root.(*accessContainer[T])
+		if root == nil {
+			return false
+		}
+		c.path = append(c.path, burstLevel[T]{root, -1})
+		if root.hasSingle {
+			return true
+		}
+	}
+	return c.forward()
+}
+
+// forward moves on to the first item held after the current record of the last level.
+func (c *burstCursor[T]) forward() bool {
+Levels:
+	for len(c.path) > 0 {
+		level := &c.path[len(c.path)-1]
+		records := &level.node.records
+		for level.index++; level.index < recordsWidth; level.index++ {
+			// skip to the next record held
+			if level.index = records.next(level.index); level.index == recordsWidth {
+				break
+			}
+			switch cur := records.get(byte(level.index)).(type) {
+			case *accessContainer[T]:
+				// go down one more level, its single item comes first
+				c.path = append(c.path, burstLevel[T]{cur, -1})
+				if cur.hasSingle {
+					return true
+				}
+				continue Levels
+			case container[T]:
+				if entries := cur.entries(); len(entries) > 0 {
+					c.entries, c.pos = entries, 0
+					return true
+				}
+			}
+		}
+		// pop
+		c.path = c.path[:len(c.path)-1]
+	}
+	return false
+}
+
+func (c *burstCursor[T]) Prev() bool {
+	if c.entries != nil && c.pos > 0 {
+		c.pos--
+		return true
+	}
+	c.entries = nil
+	if len(c.path) == 0 {
+		root, _ := c.tree.root.(*accessContainer[T])
+		if root == nil {
+			return false
+		}
+		c.path = append(c.path, burstLevel[T]{root, recordsWidth})
+	}
+	return c.backward()
+}
+
+// backward moves on to the last item held before the current record of the last level.
+func (c *burstCursor[T]) backward() bool {
+Levels:
+	for len(c.path) > 0 {
+		level := &c.path[len(c.path)-1]
+		records := &level.node.records
+		for level.index--; level.index >= 0; level.index-- {
+			// skip to the previous record held
+			if level.index = records.prev(level.index); level.index < 0 {
+				break
+			}
+			switch cur := records.get(byte(level.index)).(type) {
+			case *accessContainer[T]:
+				// go down one more level, starting from its last record
+				c.path = append(c.path, burstLevel[T]{cur, recordsWidth})
+				continue Levels
+			case container[T]:
+				if entries := cur.entries(); len(entries) > 0 {
+					c.entries, c.pos = entries, len(entries)-1
+					return true
+				}
+			}
+		}
+		// the single item comes before all records
+		if level.index == -1 && level.node.hasSingle {
+			return true
+		}
+		// pop
+		c.path = c.path[:len(c.path)-1]
+	}
+	return false
+}
+
+func (c *burstCursor[T]) Seek(item T) bool {
+	c.path, c.entries = c.path[:0], nil
+	root, _ := c.tree.root.(*accessContainer[T])
+	query := c.tree.toBytes(item)
+	if root == nil || query == nil {
+		return false
+	}
+	c.path = append(c.path, burstLevel[T]{root, -1})
+	for i := 0; ; i++ {
+		level := &c.path[len(c.path)-1]
+		segment := level.node.segment
+		if j := commonPrefix(segment, query[i:]); j < len(segment) {
+			if i+j == len(query) || segment[j] > query[i+j] {
+				// everything below this level is larger than query
+				if level.node.hasSingle {
+					return true
+				}
+				return c.forward()
+			}
+			// and here everything is smaller
+			level.index = recordsWidth
+			return c.forward()
+		}
+		i += len(segment)
+		if i == len(query) {
+			// everything below this level is larger than query
+			if level.node.hasSingle {
+				return true
+			}
+			return c.forward()
+		}
+		level.index = int(query[i])
+		switch cur := level.node.records.get(query[i]).(type) {
+		case *accessContainer[T]:
+			c.path = append(c.path, burstLevel[T]{cur, -1})
+		case container[T]:
+			entries := cur.entries()
+			suffix := query[i+1:]
+			pos, _ := slices.BinarySearchFunc(entries, suffix, func(e containerEntry[T], suffix []byte) int {
+				return bytes.Compare(e.key, suffix)
+			})
+			if pos < len(entries) {
+				c.entries, c.pos = entries, pos
+				return true
+			}
+			return c.forward()
+		case nil:
+			return c.forward()
+		}
+	}
+}
+
+// All returns an iterator over the keys and elements of the tree in the given order, InOrder being
+// the lexicographic order of the keys, for use with range:
+//
+//	for key, elem := range burst.All(InOrder) {
+//	    fmt.Println(string(key), elem)
+//	}
+//
+// Each key is a newly allocated slice. Breaking out of the loop early is fine, as no state is kept within the tree.
+func (burst *BurstTreeOf[T]) All(order TravOrder) iter.Seq2[[]byte, T] {
+	switch order {
+	case InOrder, RevOrder, AnyOrder:
+		return func(yield func([]byte, T) bool) {
+			if root, ok := burst.root.(*accessContainer[T]); ok {
+				root.all(order, nil, yield)
+			}
+		}
+	case LevelOrder:
+		return func(yield func([]byte, T) bool) {
+			root, ok := burst.root.(*accessContainer[T])
+			if !ok {
+				return
+			}
+			next := root.levelOrder()
+			for key, elem, ok := next(); ok && yield(key, elem); key, elem, ok = next() {
+			}
+		}
+	case RandOrder:
+		return func(yield func([]byte, T) bool) {
+			next := shuffle(burst.pairs(), burst.rand)
+			for e, ok := next(); ok && yield(e.key, e.item); e, ok = next() {
+			}
+		}
+	default:
+		s := fmt.Sprintf("BurstTree has not implemented %s for iteration.", order)
+		panic(s)
+	}
+}
+
+// PrefixIter returns an iterator over the keys and elements of the tree whose keys begin with prefix,
+// in the lexicographic order of the keys. At most limit elements are given, unless limit <= 0 in which
+// case there is no limit. So the top ten completions of a word are:
+//
+//	for key := range burst.PrefixIter([]byte("pre"), 10) {
+//	    fmt.Println(string(key))
+//	}
+//
+// As with All, each key is a newly allocated slice.
+func (burst *BurstTreeOf[T]) PrefixIter(prefix []byte, limit int) iter.Seq2[[]byte, T] {
+	return func(yield func([]byte, T) bool) {
+		current, ok := burst.root.(*accessContainer[T])
+		if !ok {
 			return
 		}
-		for _, v := range parent.records {
-			if v != nil { // not empty, stop
+		if limit > 0 {
+			count, inner := 0, yield
+			yield = func(key []byte, item T) bool {
+				count++
+				return inner(key, item) && count < limit
+			}
+		}
+		// follow the prefix down the access containers as far as it goes, i being
+		// the bytes of it which lead to current
+		for i := 0; ; i++ {
+			j := commonPrefix(current.segment, prefix[i:])
+			if i+j == len(prefix) {
+				// all of current is within prefix
+				current.all(InOrder, append([]byte{}, prefix[:i]...), yield)
+				return
+			}
+			if j < len(current.segment) {
 				return
 			}
+			i += j
+			switch cur := current.records.get(prefix[i]).(type) {
+			case *accessContainer[T]:
+				current = cur
+			case container[T]:
+				// the rest of the prefix must be matched within the containers suffixes
+				rest := prefix[i+1:]
+				for _, e := range cur.entries() {
+					if !bytes.HasPrefix(e.key, rest) {
+						continue
+					}
+					key := make([]byte, 0, i+1+len(e.key))
+					key = append(append(key, prefix[:i+1]...), e.key...)
+					if !yield(key, e.item) {
+						return
+					}
+				}
+				return
+			case nil:
+				return
+			}
+		}
+	}
+}
+
+// PrefixMap calls f with the key and element of each element whose key begins with prefix, in the
+// lexicographic order of the keys, stopping after limit elements when limit > 0.
+func (burst *BurstTreeOf[T]) PrefixMap(prefix []byte, limit int, f func(key []byte, item T)) {
+	for key, item := range burst.PrefixIter(prefix, limit) {
+		f(key, item)
+	}
+}
+
+// RangeIter returns an iterator over the keys and elements of the tree whose keys lie between lo and hi,
+// in the lexicographic order of the keys. Bounds chooses whether lo and hi themselves are included,
+// and a nil hi leaves the range unbounded above. Only the records of the access containers
+// between lo and hi are entered, so a small range of a large tree is cheap.
+// As with All, each key is a newly allocated slice.
+func (burst *BurstTreeOf[T]) RangeIter(lo, hi []byte, bounds Bounds) iter.Seq2[[]byte, T] {
+	return func(yield func([]byte, T) bool) {
+		if root, ok := burst.root.(*accessContainer[T]); ok {
+			r := keyRange{lo, hi, bounds}
+			root.keyRange(&r, nil, true, hi != nil, yield)
+		}
+	}
+}
+
+// RangeMap calls f with the key and element of each element whose key lies between lo and hi,
+// in the lexicographic order of the keys. See RangeIter.
+func (burst *BurstTreeOf[T]) RangeMap(lo, hi []byte, bounds Bounds, f func(key []byte, item T)) {
+	for key, item := range burst.RangeIter(lo, hi, bounds) {
+		f(key, item)
+	}
+}
+
+// the keys between lo and hi, hi being unbounded when nil
+type keyRange struct {
+	lo, hi []byte
+	bounds Bounds
+}
+
+var compareBytes = CmpFunc(bytes.Compare)
+
+func (r *keyRange) contains(key []byte) bool {
+	return r.bounds.afterLo(compareBytes(key, r.lo)) &&
+		(r.hi == nil || r.bounds.beforeHi(compareBytes(key, r.hi)))
+}
+
+// keyRange yields the items at and below this access container within r. Their keys begin with
+// prefix, which is also the start of lo when onLo, and the start of hi when onHi. Otherwise that
+// bound has been left behind, and needs no more checking.
+func (a *accessContainer[T]) keyRange(r *keyRange, prefix []byte, onLo, onHi bool, yield func([]byte, T) bool) bool {
+	// the segment may leave either bound behind, or show everything here to be out of range
+	for _, c := range a.segment {
+		d := len(prefix)
+		if onLo {
+			if d == len(r.lo) || c > r.lo[d] {
+				onLo = false
+			} else if c < r.lo[d] {
+				return true
+			}
+		}
+		if onHi {
+			if d == len(r.hi) || c > r.hi[d] {
+				return true
+			} else if c < r.hi[d] {
+				onHi = false
+			}
+		}
+		prefix = append(prefix, c)
+	}
+	d := len(prefix)
+	if a.hasSingle && (!onLo && !onHi || r.contains(prefix)) && !yield(append([]byte{}, prefix...), a.single) {
+		return false
+	}
+	start, end := 0, recordsWidth-1
+	if onLo && d < len(r.lo) {
+		start = int(r.lo[d])
+	}
+	if onHi {
+		if d == len(r.hi) {
+			// every key further down is beyond hi
+			return true
+		}
+		end = int(r.hi[d])
+	}
+	for i := a.records.next(start); i <= end; i = a.records.next(i + 1) {
+		childLo := onLo && d < len(r.lo) && i == int(r.lo[d])
+		childHi := onHi && i == int(r.hi[d])
+		switch cur := a.records.get(byte(i)).(type) {
+		case *accessContainer[T]:
+			if !cur.keyRange(r, append(prefix, byte(i)), childLo, childHi, yield) {
+				return false
+			}
+		case container[T]:
+			for _, e := range cur.entries() {
+				key := make([]byte, 0, d+1+len(e.key))
+				key = append(append(append(key, prefix...), byte(i)), e.key...)
+				if (childLo || childHi) && !r.contains(key) {
+					continue
+				}
+				if !yield(key, e.item) {
+					return false
+				}
+			}
 		}
-		// remove
-		parents[last-1].records[query[last-1]] = nil
+	}
+	return true
+}
+
+// ApproxIter returns an iterator over the keys and elements of the tree whose keys are within k edits of
+// query, an edit being the insertion, deletion or substitution of a byte. Like All, the keys are given
+// in lexicographic order and each is newly allocated. Any branch of the tree which cannot come within
+// k edits is skipped, so small values of k are cheap.
+//
+//	for key := range burst.ApproxIter([]byte("speling"), 2) {
+//	    fmt.Println(string(key))
+//	}
+func (burst *BurstTreeOf[T]) ApproxIter(query []byte, k int) iter.Seq2[[]byte, T] {
+	return func(yield func([]byte, T) bool) {
+		root, ok := burst.root.(*accessContainer[T])
+		if !ok || k < 0 {
+			return
+		}
+		// the distances from the empty key to each prefix of query
+		row := make([]int, len(query)+1)
+		for j := range row {
+			row[j] = j
+		}
+		root.approx(query, k, nil, row, yield)
+	}
+}
 
+// ApproxMap calls f with the key and element of each element whose key is within k edits of query,
+// in the lexicographic order of the keys. See ApproxIter.
+func (burst *BurstTreeOf[T]) ApproxMap(query []byte, k int, f func(key []byte, item T)) {
+	for key, item := range burst.ApproxIter(query, k) {
+		f(key, item)
+	}
+}
+
+// editRow fills next with the edit distances from key+c to each prefix of query, given
+// the distances in row from key. It returns the smallest of them, a lower bound on the
+// distance of any key beginning with key+c.
+func editRow(query []byte, row, next []int, c byte) (min int) {
+	next[0] = row[0] + 1
+	min = next[0]
+	for j := 1; j < len(next); j++ {
+		cost := row[j-1]
+		if query[j-1] != c {
+			cost++
+		}
+		if row[j]+1 < cost {
+			cost = row[j] + 1
+		}
+		if next[j-1]+1 < cost {
+			cost = next[j-1] + 1
+		}
+		next[j] = cost
+		if cost < min {
+			min = cost
+		}
 	}
 	return
 }
 
-func (burst *BurstTree) Next() (next Byte) {
+// approx yields the items at and below this access container within k edits of query. Their keys begin
+// with prefix, and row holds the edit distances from prefix to each prefix of query.
+func (a *accessContainer[T]) approx(query []byte, k int, prefix []byte, row []int, yield func([]byte, T) bool) bool {
+	for _, c := range a.segment {
+		next := make([]int, len(row))
+		if editRow(query, row, next, c) > k {
+			return true
+		}
+		row, prefix = next, append(prefix, c)
+	}
+	if a.hasSingle && row[len(query)] <= k && !yield(append([]byte{}, prefix...), a.single) {
+		return false
+	}
+	next := make([]int, len(row))
+	for i, record := range a.records.all() {
+		// prune any branch which is already too far away
+		if editRow(query, row, next, byte(i)) > k {
+			continue
+		}
+		switch cur := record.(type) {
+		case *accessContainer[T]:
+			if !cur.approx(query, k, append(prefix, byte(i)), next, yield) {
+				return false
+			}
+		case container[T]:
+			// each suffix continues on from next, one row per byte
+			rows := [][]int{next}
+		Entries:
+			for _, e := range cur.entries() {
+				for d, c := range e.key {
+					if len(rows) == d+1 {
+						rows = append(rows, make([]int, len(row)))
+					}
+					if editRow(query, rows[d], rows[d+1], c) > k {
+						continue Entries
+					}
+				}
+				if rows[len(e.key)][len(query)] > k {
+					continue
+				}
+				key := make([]byte, 0, len(prefix)+1+len(e.key))
+				key = append(append(append(key, prefix...), byte(i)), e.key...)
+				if !yield(key, e.item) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// all yields the items held at and below this access container, whose keys all begin with prefix,
+// in InOrder, RevOrder or AnyOrder.
+func (a *accessContainer[T]) all(order TravOrder, prefix []byte, yield func([]byte, T) bool) bool {
+	prefix = append(prefix, a.segment...)
+	if a.hasSingle && order != RevOrder && !yield(append([]byte{}, prefix...), a.single) {
+		return false
+	}
+	records := a.records.all()
+	if order == RevOrder {
+		records = a.records.backward()
+	}
+	for i, record := range records {
+		switch cur := record.(type) {
+		case *accessContainer[T]:
+			if !cur.all(order, append(prefix, byte(i)), yield) {
+				return false
+			}
+		case container[T]:
+			next := cur.iter(order)
+			for suffix, item, ok := next(); ok; suffix, item, ok = next() {
+				key := make([]byte, 0, len(prefix)+1+len(suffix))
+				key = append(append(append(key, prefix...), byte(i)), suffix...)
+				if !yield(key, item) {
+					return false
+				}
+			}
+		}
+	}
+	return !a.hasSingle || order != RevOrder || yield(append([]byte{}, prefix...), a.single)
+}
+
+func (burst *BurstTreeOf[T]) Next() (next T, ok bool) {
+	if burst.iterNext == nil {
+		return
+	}
 	return burst.iterNext()
 }
 
-func (burst *BurstTree) IterInit(order TravOrder) (start Byte) {
+// Key returns the key of the element last returned by IterInit or Next, as rebuilt from the
+// path down the tree rather than by asking the element for it. Once the iteration is done it returns nil.
+// The slice is reused by the iterator, so it is only valid until the following call to Next.
+//
+//	for x, ok := burst.IterInit(InOrder); ok; x, ok = burst.Next() {
+//	    fmt.Println(string(burst.Key()), x)
+//	}
+func (burst *BurstTreeOf[T]) Key() []byte {
+	return burst.iterKey
+}
+
+func (burst *BurstTreeOf[T]) IterInit(order TravOrder) (start T, ok bool) {
 
 	type iter struct {
 		index int
-		it    *accessContainer
+		it    *accessContainer[T]
+		depth int // length of the key down to it
 	}
 
 	//TODO: test and corner case elmination
-	//TODO: output key as well
+	burst.iterKey = nil
 	if burst.root == nil {
-		start = nil
 		return
 	}
-	var cIter func() ([]byte, Byte)
-	// should we output from a container
+	var cIter func() ([]byte, T, bool)
+	// should we output from a container, and the record it sits in
 	isC := false
+	var cByte byte
 
-	current := burst.root.(*accessContainer)
+	current := burst.root.(*accessContainer[T])
 	stack := []iter{}
+	// the key down to current, the record and segment of each level of the stack
+	prefix := []byte{}
+	key := []byte{}
 
 	index := -1
 	switch order {
-	case InOrder:
-		burst.iterNext = func() (out Byte) {
+	case InOrder, AnyOrder:
+		burst.iterNext = func() (out T, ok bool) {
 			// we need to keep trying to go down levels, once we hit either a nill or container,
 			// we need to either output all the containers items in order or ignore the nil.
 			// Then continue traversing the rest of the record array.
@@ -264,9 +1349,11 @@ func (burst *BurstTree) IterInit(order TravOrder) (start Byte) {
 			for {
 				// output containers items first
 				if isC {
-					// TODO stop ignoring key
-					_, out = cIter()
-					if out != nil {
+					var suffix []byte
+					suffix, out, ok = cIter()
+					if ok {
+						key = append(append(append(key[:0], prefix...), cByte), suffix...)
+						burst.iterKey = key
 						return
 					} else {
 						isC = false
@@ -277,24 +1364,33 @@ func (burst *BurstTree) IterInit(order TravOrder) (start Byte) {
 				// output an empty string before more traversal
 				if index == -1 {
 					index++
-					if current.single != nil {
-						out = current.single
+					if current.hasSingle {
+						out, ok = current.single, true
+						key = append(key[:0], prefix...)
+						burst.iterKey = key
 						break
 					}
 				}
-				for index < 255 {
-					switch cur := current.records[index].(type) {
-					case *accessContainer:
+				for index < recordsWidth {
+					// skip to the next record held
+					if index = current.records.next(index); index == recordsWidth {
+						break
+					}
+					switch cur := current.records.get(byte(index)).(type) {
+					case *accessContainer[T]:
 
+						depth := len(prefix)
+						prefix = append(append(prefix, byte(index)), cur.segment...)
 						index++
-						stack = append(stack, iter{index, current})
+						stack = append(stack, iter{index, current, depth})
 						current = cur // go down one more level
 						index = -1
 						goto Dive
-					case container:
+					case container[T]:
 						cIter = cur.iter(order)
 						if cIter != nil {
 							isC = true
+							cByte = byte(index)
 						}
 						index++
 						goto Dive
@@ -308,27 +1404,289 @@ func (burst *BurstTree) IterInit(order TravOrder) (start Byte) {
 					s := stack[stackIndex]
 					current, index = s.it, s.index
 					stack = stack[0:stackIndex]
+					prefix = prefix[0:s.depth]
 				} else {
-					out = nil
 					// last node, reset
 					burst.iterNext = nil
+					burst.iterKey = nil
 					return
 
 				}
 			}
-			return out
+			return
 		}
 		return burst.iterNext()
 	case RevOrder:
-		//TODO
+		// the mirror image of InOrder, walking the records from the last down to the first
+		// and leaving the empty string until after the rest of the level. An index of -1
+		// means only the empty string remains, and -2 that the level is done.
+		index = recordsWidth - 1
+		burst.iterNext = func() (out T, ok bool) {
+		Dive:
+			for {
+				// output containers items first
+				if isC {
+					var suffix []byte
+					suffix, out, ok = cIter()
+					if ok {
+						key = append(append(append(key[:0], prefix...), cByte), suffix...)
+						burst.iterKey = key
+						return
+					} else {
+						isC = false
+					}
+
+				}
+
+				for index >= 0 {
+					// skip to the previous record held
+					if index = current.records.prev(index); index < 0 {
+						break
+					}
+					switch cur := current.records.get(byte(index)).(type) {
+					case *accessContainer[T]:
+
+						depth := len(prefix)
+						prefix = append(append(prefix, byte(index)), cur.segment...)
+						index--
+						stack = append(stack, iter{index, current, depth})
+						current = cur // go down one more level
+						index = recordsWidth - 1
+						goto Dive
+					case container[T]:
+						cIter = cur.iter(order)
+						if cIter != nil {
+							isC = true
+							cByte = byte(index)
+						}
+						index--
+						goto Dive
+					case nil:
+						index--
+					}
+				}
 
+				// output an empty string after all other traversal
+				if index == -1 {
+					index--
+					if current.hasSingle {
+						out, ok = current.single, true
+						key = append(key[:0], prefix...)
+						burst.iterKey = key
+						break
+					}
+				}
+				if len(stack) > 0 {
+					// pop
+					stackIndex := len(stack) - 1
+					s := stack[stackIndex]
+					current, index = s.it, s.index
+					stack = stack[0:stackIndex]
+					prefix = prefix[0:s.depth]
+				} else {
+					// last node, reset
+					burst.iterNext = nil
+					burst.iterKey = nil
+					return
+
+				}
+			}
+			return
+		}
+		return burst.iterNext()
+	case LevelOrder:
+		next := current.levelOrder()
+		burst.iterNext = func() (out T, ok bool) {
+			burst.iterKey, out, ok = next()
+			// last node, reset
+			if !ok {
+				burst.iterNext = nil
+			}
+			return
+		}
+		return burst.iterNext()
+	case RandOrder:
+		next := shuffle(burst.pairs(), burst.rand)
+		burst.iterNext = func() (out T, ok bool) {
+			var e containerEntry[T]
+			e, ok = next()
+			out, burst.iterKey = e.item, e.key
+			// last node, reset
+			if !ok {
+				burst.iterNext = nil
+			}
+			return
+		}
+		return burst.iterNext()
 	default:
 		s := fmt.Sprintf("BurstTree has not implemented %s for iteration.", order)
 		panic(s)
 	}
+}
+
+// Map is a more performance orientated way to iterate over the elements of the tree.
+// Given a TravOrder and a function to call with each element, Map calls the function
+// for each element in the specified order, InOrder being the lexicographic order of the keys.
+func (burst *BurstTreeOf[T]) Map(order TravOrder, f func(T)) {
+	burst.MapKeys(order, func(key []byte, elem T) {
+		f(elem)
+	})
+}
+
+// MapKeys is Map for when the keys are wanted as well, f being called with each element and
+// the key it is stored under. The key may be reused once f returns, so f must copy it to keep it.
+func (burst *BurstTreeOf[T]) MapKeys(order TravOrder, f func(key []byte, elem T)) {
+	root, ok := burst.root.(*accessContainer[T])
+	if !ok {
+		return
+	}
+	switch order {
+	case InOrder, RevOrder, AnyOrder:
+		root.walk(order, []byte{}, f)
+	case LevelOrder:
+		next := root.levelOrder()
+		for key, elem, ok := next(); ok; key, elem, ok = next() {
+			f(key, elem)
+		}
+	case RandOrder:
+		next := shuffle(burst.pairs(), burst.rand)
+		for e, ok := next(); ok; e, ok = next() {
+			f(e.key, e.item)
+		}
+	default:
+		s := fmt.Sprintf("BurstTree has not implemented %s.", order)
+		panic(s)
+	}
+}
+
+// SetRand chooses the source of randomness used by RandOrder traversals, nil being the global
+// math/rand source. A *rand.Rand is not safe for concurrent use, so neither are RandOrder traversals sharing one.
+func (burst *BurstTreeOf[T]) SetRand(r *rand.Rand) {
+	burst.rand = r
+}
+
+// all the keys and elements, for the orders which must see everything before starting
+func (burst *BurstTreeOf[T]) pairs() []containerEntry[T] {
+	pairs := make([]containerEntry[T], 0, burst.size)
+	for key, elem := range burst.All(InOrder) {
+		pairs = append(pairs, containerEntry[T]{key, elem})
+	}
+	return pairs
+}
+
+// walk calls f with the items at and below this access container, all of whose keys begin with prefix.
+// The empty string comes first unless the order is RevOrder, in which case it comes last.
+func (a *accessContainer[T]) walk(order TravOrder, prefix []byte, f func([]byte, T)) {
+	prefix = append(prefix, a.segment...)
+	if a.hasSingle && order != RevOrder {
+		f(prefix, a.single)
+	}
+	records := a.records.all()
+	if order == RevOrder {
+		records = a.records.backward()
+	}
+	for i, record := range records {
+		switch cur := record.(type) {
+		case *accessContainer[T]:
+			cur.walk(order, append(prefix, byte(i)), f)
+		case container[T]:
+			next := cur.iter(order)
+			for suffix, item, ok := next(); ok; suffix, item, ok = next() {
+				f(append(append(prefix, byte(i)), suffix...), item)
+			}
+		}
+	}
+	if a.hasSingle && order == RevOrder {
+		f(prefix, a.single)
+	}
+}
+
+// levelOrder visits the access containers breadth first, each one giving up its single and then
+// the items of its containers before any deeper access container is seen. So shorter keys tend to come first.
+// Each key is newly allocated.
+func (a *accessContainer[T]) levelOrder() func() ([]byte, T, bool) {
+	type level struct {
+		prefix []byte
+		it     *accessContainer[T]
+	}
+	queue := []level{{append([]byte{}, a.segment...), a}}
+	var pending []containerEntry[T]
+	return func() (key []byte, out T, ok bool) {
+		for len(pending) == 0 {
+			if len(queue) == 0 {
+				return
+			}
+			// dequeue
+			current := queue[0]
+			queue = queue[1:]
+			if current.it.hasSingle {
+				pending = append(pending, containerEntry[T]{current.prefix, current.it.single})
+			}
+			for i, record := range current.it.records.all() {
+				prefix := make([]byte, 0, len(current.prefix)+1)
+				prefix = append(append(prefix, current.prefix...), byte(i))
+				switch cur := record.(type) {
+				case *accessContainer[T]:
+					queue = append(queue, level{append(prefix, cur.segment...), cur})
+				case container[T]:
+					for _, e := range cur.entries() {
+						key := make([]byte, 0, len(prefix)+len(e.key))
+						pending = append(pending, containerEntry[T]{append(append(key, prefix...), e.key...), e.item})
+					}
+				}
+			}
+		}
+		key, out, ok = pending[0].key, pending[0].item, true
+		pending = pending[1:]
+		return
+	}
+}
+
+// Cursor returns a new unpositioned cursor over the tree. Elements are visited in the
+// lexicographic order of their keys. See CursorOf for an example.
+func (burst *BurstTree) Cursor() ByteCursor {
+	return byteCursor{burst.BurstTreeOf.Cursor()}
+}
+
+func (burst *BurstTree) Search(item Byte) (found Byte) {
+	if item == nil {
+		return
+	}
+	found, _ = burst.BurstTreeOf.Search(item)
+	return
+}
+
+func (burst *BurstTree) LongestPrefix(query []byte) (found Byte) {
+	found, _ = burst.BurstTreeOf.LongestPrefix(query)
+	return
+}
+
+func (burst *BurstTree) Insert(item Byte) (old Byte) {
+	if item == nil {
+		return
+	}
+	old, _ = burst.BurstTreeOf.Insert(item)
+	return
+}
+
+func (burst *BurstTree) Remove(item Byte) (old Byte) {
+	if item == nil {
+		return
+	}
+	old, _ = burst.BurstTreeOf.Remove(item)
+	return
+}
+
+func (burst *BurstTree) Next() (next Byte) {
+	next, _ = burst.BurstTreeOf.Next()
+	return
+}
+
+func (burst *BurstTree) IterInit(order TravOrder) (start Byte) {
+	start, _ = burst.BurstTreeOf.IterInit(order)
 	return
 }
 
 func (burst *BurstTree) Map(order TravOrder, f ByteIterFunc) {
-	//TODO
+	burst.BurstTreeOf.Map(order, f)
 }
@@ -3,10 +3,14 @@ package gotree
 import (
 	"bytes"
 	"container/list"
+	"encoding/binary"
 	"fmt"
 	"github.com/davecgh/go-spew/spew"
+	"math/rand"
 	"runtime"
-	"testing"
+	"slices"
+	"sort"
+	"time"
 )
 
 // TODO remove
@@ -16,148 +20,318 @@ var _ = spew.Dump
 // container is the type which allows us to switch out leaf node containers for a burst tree.
 // internal for it has alot of burst tree specific corner cases and shouldnt be considered a full dictionary stucture.
 // all methods must consider empty suffix parameter
-type container interface {
-	search(suffix []byte) (found Byte)
-	remove(suffix []byte) (old Byte)
+type container[T any] interface {
+	search(suffix []byte) (found T, ok bool)
+	// the item with the longest suffix which is a prefix of query, the empty suffix included
+	longestPrefix(query []byte) (found T, ok bool)
+	remove(suffix []byte) (old T, ok bool)
 	// must replace this containers parent if newParent != nil, this is because this method
-	// might add to the tree depth if it feels the need to burst
-	insert(suffix []byte, item Byte) (old Byte, newParent *accessContainer)
-	// key ,value
-	iter(TravOrder) func() ([]byte, Byte)
+	// might add to the tree depth if it feels the need to burst, as p decides
+	insert(suffix []byte, item T, p *BurstConfig) (old T, ok bool, newParent *accessContainer[T])
+	// key ,value, false once exhausted
+	iter(TravOrder) func() ([]byte, T, bool)
+	// all suffixes and their items in order, the empty suffix first.
+	// must not modify the container, so it can be used concurrently by cursors
+	entries() []containerEntry[T]
 	isEmpty() bool
+	burstable
 }
 
-const (
-	maxLen    int = 2<<14 - 1
-	lenOffset int = 2
-)
+// what a burst policy looks at, see BurstConfig.full
+type burstable interface {
+	size() int      // items held, not counting the empty suffix
+	footprint() int // bytes taken by the suffixes
+	counts() *accessStats
+}
 
-type compactArray struct {
-	single Byte
-	items  []Byte
-	// length prefixed, logically seperated byte strings
-	// a compact reprsentation of strings.
-	records []byte
+// accessStats counts the searches and insertions which reach a container, only kept under RatioPolicy.
+type accessStats struct {
+	accesses int
+	misses   int // accesses for a suffix not held
 }
 
-func (c *compactArray) sort(order TravOrder) {
-	if len(c.items) == 0 {
-		return
+func (s *accessStats) record(missed bool) {
+	s.accesses++
+	if missed {
+		s.misses++
 	}
+}
 
-	switch order {
-	case AnyOrder:
-		return
-	}
-	type sorter struct {
-		suffix []byte
-		item   Byte
-	}
-	//newRec := make([]byte, 0, len(c.records))
-	tempRec := make([]sorter, len(c.items))
-	dend, dstart, suffixCount, recLen := 0, 0, 0, len(c.records)
+// A Container is a leaf of a BurstTree, a small dictionary of the items whose keys all begin with the
+// path down to it, each held under the rest of its key. Give WithContainer a function making them to have
+// a BurstTree use your own. The tree takes care of bursting a Container which grows too big,
+// spreading its items over new Containers one level further down.
+//
+// Suffixes may be empty. They belong to the caller, so must be copied if they are to be kept.
+type Container[T any] interface {
+	Search(suffix []byte) (found T, ok bool)
+	// Insert adds item under suffix, replacing and returning any item already there.
+	Insert(suffix []byte, item T) (old T, ok bool)
+	Remove(suffix []byte) (old T, ok bool)
+	Len() int
+	// Entries returns every suffix and its item, in any order.
+	Entries() []ContainerEntry[T]
+}
 
-	// write into stuct array
-	for {
-		// compute offsets
-		dlen := int((c.records[dend]) | (c.records[dend+1])<<8)
-		skip := lenOffset + dlen
+// A ContainerEntry is an item together with the suffix it is held under.
+type ContainerEntry[T any] struct {
+	Key  []byte
+	Item T
+}
 
-		tempRec[suffixCount] = sorter{c.records[dstart+lenOffset : (dend + skip)], c.items[suffixCount]}
-		dend += skip
-		dstart += skip //move our indexs
-		suffixCount++  // keep suffix index in sync
+// customContainer adapts a users Container for use within the tree.
+type customContainer[T any] struct {
+	Container[T]
+	newContainer func() Container[T] // for bursting into more of the same
+	stats        accessStats
+}
 
-		// search failed
-		if dend == recLen {
-			break
+// size leaves out the empty suffix, as the other containers do, though Len counts it.
+func (c *customContainer[T]) size() int {
+	if _, ok := c.Search(nil); ok {
+		return c.Len() - 1
+	}
+	return c.Len()
+}
+
+func (c *customContainer[T]) footprint() (n int) {
+	for _, e := range c.Entries() {
+		if len(e.Key) > 0 {
+			n += lenWidth(len(e.Key)) + len(e.Key)
 		}
 	}
-	//TODO  avoid sort if already sorted by checking on the prior pass of data
-	switch order {
-	case InOrder:
+	return
+}
 
-		for i := 1; i < len(c.items); i++ {
-			for j := i; j > 0 && bytes.Compare(tempRec[j].suffix, tempRec[j-1].suffix) < 0; j-- {
-				tempRec[j-1], tempRec[j] = tempRec[j], tempRec[j-1]
-			}
+func (c *customContainer[T]) counts() *accessStats {
+	return &c.stats
+}
+
+func (c *customContainer[T]) search(suffix []byte) (found T, ok bool) {
+	return c.Search(suffix)
+}
+
+func (c *customContainer[T]) remove(suffix []byte) (old T, ok bool) {
+	return c.Remove(suffix)
+}
+
+func (c *customContainer[T]) isEmpty() bool {
+	return c.Len() == 0
+}
+
+func (c *customContainer[T]) insert(suffix []byte, item T, p *BurstConfig) (old T, ok bool, newParent *accessContainer[T]) {
+	old, ok = c.Insert(suffix, item)
+	// check if we need to burst
+	if ok || !p.full(c) {
+		return
+	}
+	// add more depth to tree
+	entries := c.unsorted()
+	newParent = &accessContainer[T]{segment: segmentOf(entries)}
+	for _, e := range entries {
+		key := e.key[len(newParent.segment):]
+		if len(key) == 0 {
+			// transfer empty string
+			newParent.single, newParent.hasSingle = e.item, true
+			continue
 		}
-	case RevOrder:
+		// if we have not created a new child yet create new child
+		child, _ := newParent.records.get(key[0]).(*customContainer[T])
+		if child == nil {
+			child = &customContainer[T]{Container: c.newContainer(), newContainer: c.newContainer}
+			newParent.records.set(key[0], child)
+		}
+		child.Insert(key[1:], e.item)
+	}
+	return
+}
 
-		for i := 1; i < len(c.items); i++ {
-			for j := i; j > 0 && bytes.Compare(tempRec[j].suffix, tempRec[j-1].suffix) > 0; j-- {
-				tempRec[j], tempRec[j-1] = tempRec[j-1], tempRec[j]
-			}
+func (c *customContainer[T]) longestPrefix(query []byte) (found T, ok bool) {
+	best := -1
+	for _, e := range c.Entries() {
+		if len(e.Key) > best && bytes.HasPrefix(query, e.Key) {
+			found, ok, best = e.Item, true, len(e.Key)
 		}
 	}
+	return
+}
 
-	c.records = nil
-	c.items = nil
-	for _, x := range tempRec {
+func (c *customContainer[T]) entries() []containerEntry[T] {
+	entries := c.unsorted()
+	sortEntries(entries)
+	return entries
+}
 
-		c.extend(x.suffix, x.item)
+func (c *customContainer[T]) unsorted() []containerEntry[T] {
+	custom := c.Entries()
+	entries := make([]containerEntry[T], len(custom))
+	for i, e := range custom {
+		entries[i] = containerEntry[T]{e.Key, e.Item}
 	}
+	return entries
+}
 
+func (c *customContainer[T]) iter(order TravOrder) (fn func() ([]byte, T, bool)) {
+	if order == AnyOrder {
+		return iterEntries(c.unsorted(), order)
+	}
+	return iterEntries(c.entries(), order)
 }
 
-func (c *compactArray) iter(order TravOrder) (fn func() ([]byte, Byte)) {
-	//TODO RevOrder
+// a suffix and its item as held within a container
+type containerEntry[T any] struct {
+	key  []byte
+	item T
+}
 
-	c.sort(order)
-	dend, dstart, suffixCount, notDone, recLen, singleOut := 0, 0, 0, true, len(c.records), false
-	return func() (key []byte, found Byte) {
+// sort entries by their suffix, the empty suffix first
+func sortEntries[T any](entries []containerEntry[T]) {
+	slices.SortFunc(entries, func(a, b containerEntry[T]) int {
+		return bytes.Compare(a.key, b.key)
+	})
+}
 
-		if !singleOut {
-			singleOut = true
-			if c.single != nil {
-				return []byte{}, c.single
-			}
-		}
-		if len(c.records) == 0 {
-			return nil, nil
-		}
+// segmentOf is the longest prefix shared by the suffixes of all the entries, which becomes the segment
+// of the access container a burst makes. Then a run of bytes common to the whole container takes up
+// one access container rather than one for each byte.
+func segmentOf[T any](entries []containerEntry[T]) []byte {
+	if len(entries) == 0 {
+		return nil
+	}
+	segment := entries[0].key
+	for _, e := range entries[1:] {
+		segment = segment[:commonPrefix(segment, e.key)]
+	}
+	return append([]byte(nil), segment...)
+}
 
-		for notDone {
-			// compute offsets
-			dlen := int((c.records[dend]) | (c.records[dend+1])<<8)
-			skip := lenOffset + dlen
-			key = c.records[dstart+lenOffset : (dend + skip)] // get string
-			found = c.items[suffixCount]
-			dend += skip
-			dstart += skip //move our indexs
-			suffixCount++  // keep suffix index in sync
+// commonPrefix is the length of the longest prefix of both a and b.
+func commonPrefix(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
 
-			// cant break, we need to output nil to signal were done iterating
-			if dend == recLen {
-				notDone = false
-			}
+// iterEntries steps through entries, backwards for RevOrder.
+func iterEntries[T any](entries []containerEntry[T], order TravOrder) func() ([]byte, T, bool) {
+	i := 0
+	return func() (key []byte, found T, ok bool) {
+		if i == len(entries) {
 			return
 		}
-		return nil, nil
+		e := entries[i]
+		if order == RevOrder {
+			e = entries[len(entries)-1-i]
+		}
+		i++
+		return e.key, e.item, true
+	}
+}
+
+// Suffixes are length prefixed within the records of the compact containers. The length is a uvarint,
+// so a suffix of any length may be held while a short one only takes a byte more.
+
+// appendLen appends the length prefix of a suffix of n bytes.
+func appendLen(records []byte, n int) []byte {
+	return binary.AppendUvarint(records, uint64(n))
+}
+
+// readLen decodes the length prefix at the start of records, returning the length of the suffix
+// which follows along with the width of the prefix.
+func readLen(records []byte) (dlen, width int) {
+	n, width := binary.Uvarint(records)
+	return int(n), width
+}
+
+// lenWidth is the number of bytes taken by the length prefix of a suffix of n bytes.
+func lenWidth(n int) int {
+	width := 1
+	for ; n >= 0x80; n >>= 7 {
+		width++
+	}
+	return width
+}
+
+type compactArray[T any] struct {
+	single    T
+	hasSingle bool
+	items     []T
+	// length prefixed, logically seperated byte strings
+	// a compact reprsentation of strings.
+	records []byte
+	stats   accessStats
+}
+
+func (c *compactArray[T]) size() int {
+	return len(c.items)
+}
+
+func (c *compactArray[T]) footprint() int {
+	return len(c.records)
+}
+
+func (c *compactArray[T]) counts() *accessStats {
+	return &c.stats
+}
+
+func (c *compactArray[T]) iter(order TravOrder) (fn func() ([]byte, T, bool)) {
+	if order == AnyOrder {
+		return iterEntries(c.unsorted(), order)
+	}
+	return iterEntries(c.entries(), order)
+}
+
+func (c *compactArray[T]) entries() []containerEntry[T] {
+	entries := c.unsorted()
+	sortEntries(entries)
+	return entries
+}
+
+// the entries in the order they are stored, the empty suffix first
+func (c *compactArray[T]) unsorted() []containerEntry[T] {
+	entries := make([]containerEntry[T], 0, len(c.items)+1)
+	if c.hasSingle {
+		entries = append(entries, containerEntry[T]{[]byte{}, c.single})
+	}
+	dend, dstart, suffixCount, recLen := 0, 0, 0, len(c.records)
+	for dend < recLen {
+		// compute offsets
+		dlen, width := readLen(c.records[dend:])
+		skip := width + dlen
+		key := c.records[dstart+width : (dend + skip)] // get string
+		entries = append(entries, containerEntry[T]{key, c.items[suffixCount]})
+		dend += skip
+		dstart += skip //move our indexs
+		suffixCount++  // keep suffix index in sync
 	}
+	return entries
 }
 
-func (c *compactArray) isEmpty() bool {
-	if c.single != nil || len(c.records) > 0 {
+func (c *compactArray[T]) isEmpty() bool {
+	if c.hasSingle || len(c.records) > 0 {
 		return false
 	}
 	return true
 }
 
-func (c *compactArray) extend(suffix []byte, item Byte) {
+func (c *compactArray[T]) extend(suffix []byte, item T) {
 
 	checkLen := len(suffix)
-	c.records = append(c.records, byte(checkLen), byte(checkLen>>8))
+	c.records = appendLen(c.records, checkLen)
 	c.records = append(c.records, suffix...)
 	c.items = append(c.items, item)
 }
 
-func (c *compactArray) insert(suffix []byte, item Byte) (old Byte, newParent *accessContainer) {
+func (c *compactArray[T]) insert(suffix []byte, item T, p *BurstConfig) (old T, ok bool, newParent *accessContainer[T]) {
 
 	// empty string case
 	if len(suffix) == 0 {
-		old = c.single
-		c.single = item
+		old, ok = c.single, c.hasSingle
+		c.single, c.hasSingle = item, true
 		return
 	}
 
@@ -165,20 +339,20 @@ func (c *compactArray) insert(suffix []byte, item Byte) (old Byte, newParent *ac
 	recLen := len(c.records)
 
 	// too big for whats inside
-	if checkLen+lenOffset > recLen {
+	if lenWidth(checkLen)+checkLen > recLen {
 		c.extend(suffix, item)
 	} else {
 		dend, dstart, suffixCount := 0, 0, 0
 		for {
 			// compute offsets
-			dlen := int((c.records[dend]) | (c.records[dend+1])<<8)
-			skip := lenOffset + dlen
-			strRemain := c.records[dstart+lenOffset : (dend + skip)] // get string
+			dlen, width := readLen(c.records[dend:])
+			skip := width + dlen
+			strRemain := c.records[dstart+width : (dend + skip)] // get string
 
 			if len(strRemain) == len(suffix) {
 				dtest := bytes.Equal(strRemain, suffix)
 				if dtest {
-					old = c.items[suffixCount]
+					old, ok = c.items[suffixCount], true
 					c.items[suffixCount] = item
 					return
 				}
@@ -196,45 +370,51 @@ func (c *compactArray) insert(suffix []byte, item Byte) (old Byte, newParent *ac
 		}
 	}
 	// check if we need to burst
-	if len(c.items) > containerMax {
+	if p.full(c) {
 
 		// add more depth to tree
-		newParent = &accessContainer{}
+		newParent = &accessContainer[T]{segment: segmentOf(c.unsorted())}
 		// Begin transfering to new depth
-		var newContainer *compactArray
+		var newContainer *compactArray[T]
 
 		// transfer empty string
-		newParent.single = c.single
+		newParent.single, newParent.hasSingle = c.single, c.hasSingle
 		// we need new lenth since we inserted prior
 		recLen := len(c.records)
 		dend, dstart, suffixCount := 0, 0, 0
 		for {
 
 			// compute offsets
-			dlen := int((c.records[dend]) | (c.records[dend+1])<<8)
-			skip := lenOffset + dlen
-			elem := c.records[dstart+lenOffset : (dend + skip)] // get string
-
-			// byte to be removed
-			index := elem[0]
-			// remove byte
-			elem = elem[1:]
-
-			// if we have not created a new child yet create new child
-			// first check for empty string case
-			if newParent.records[index] == nil {
-				newContainer = &compactArray{}
-				// set new child
-				newParent.records[index] = newContainer
-			} else {
-				newContainer = newParent.records[index].(*compactArray)
-			}
+			dlen, width := readLen(c.records[dend:])
+			skip := width + dlen
+			elem := c.records[dstart+width : (dend + skip)] // get string
+			elem = elem[len(newParent.segment):]
 
 			if len(elem) == 0 {
-				newContainer.single = c.items[suffixCount]
+				// all of it was the segment
+				newParent.single, newParent.hasSingle = c.items[suffixCount], true
 			} else {
-				newContainer.extend(elem, c.items[suffixCount])
+				// byte to be removed
+				index := elem[0]
+				// remove byte
+				elem = elem[1:]
+
+				// if we have not created a new child yet create new child
+				// first check for empty string case
+				if newParent.records.get(index) == nil {
+					newContainer = &compactArray[T]{}
+					// set new child
+					newParent.records.set(index, newContainer)
+				} else {
+					newContainer = newParent.records.get(index).(*compactArray[T])
+				}
 
+				if len(elem) == 0 {
+					newContainer.single, newContainer.hasSingle = c.items[suffixCount], true
+				} else {
+					newContainer.extend(elem, c.items[suffixCount])
+
+				}
 			}
 			dend += skip
 			dstart += skip
@@ -254,35 +434,30 @@ func (c *compactArray) insert(suffix []byte, item Byte) (old Byte, newParent *ac
 	return
 }
 
-func (c *compactArray) search(suffix []byte) (found Byte) {
+func (c *compactArray[T]) search(suffix []byte) (found T, ok bool) {
 	// take care of empty string case
 	if len(suffix) == 0 {
-		return c.single
+		return c.single, c.hasSingle
 	}
-	if len(suffix) > maxLen {
-		return
-	}
-
 	checkLen := len(suffix)
 	recLen := len(c.records)
 
 	// too big for whats inside
-	if checkLen+lenOffset > recLen {
+	if lenWidth(checkLen)+checkLen > recLen {
 		return
 	}
 
 	var dend, dstart, suffixCount int
 	for {
 		// compute offsets
-		dlen := int((c.records[dend]) | (c.records[dend+1])<<8)
-		skip := lenOffset + dlen
-		strRemain := c.records[dstart+lenOffset : (dend + skip)] // get string
+		dlen, width := readLen(c.records[dend:])
+		skip := width + dlen
+		strRemain := c.records[dstart+width : (dend + skip)] // get string
 		// TODO is this duplicating work by bytes.Equal?
 		if len(strRemain) == len(suffix) {
 			dtest := bytes.Equal(strRemain, suffix)
 			if dtest {
-				found = c.items[suffixCount]
-				return
+				return c.items[suffixCount], true
 			}
 		}
 		dend += skip
@@ -296,11 +471,30 @@ func (c *compactArray) search(suffix []byte) (found Byte) {
 	}
 }
 
-func (c *compactArray) remove(suffix []byte) (found Byte) {
+func (c *compactArray[T]) longestPrefix(query []byte) (found T, ok bool) {
+	found, ok = c.single, c.hasSingle
+	best := 0
+	dend, dstart, suffixCount, recLen := 0, 0, 0, len(c.records)
+	for dend < recLen {
+		// compute offsets
+		dlen, width := readLen(c.records[dend:])
+		skip := width + dlen
+		if dlen > best && bytes.HasPrefix(query, c.records[dstart+width:(dend+skip)]) {
+			found, ok, best = c.items[suffixCount], true, dlen
+		}
+		dend += skip
+		dstart += skip //move our indexs
+		suffixCount++  // keep suffix index in sync
+	}
+	return
+}
+
+func (c *compactArray[T]) remove(suffix []byte) (found T, ok bool) {
 	// take care of empty string case
 	if len(suffix) == 0 {
-		found = c.single
-		c.single = nil
+		var zero T
+		found, ok = c.single, c.hasSingle
+		c.single, c.hasSingle = zero, false
 		return
 	}
 
@@ -308,21 +502,21 @@ func (c *compactArray) remove(suffix []byte) (found Byte) {
 	recLen := len(c.records)
 
 	// too big for whats inside
-	if checkLen+lenOffset > recLen {
+	if lenWidth(checkLen)+checkLen > recLen {
 		return
 	}
 
 	var dend, dstart, suffixCount int
 	for {
 		// compute offsets
-		dlen := int((c.records[dend]) | (c.records[dend+1])<<8)
-		skip := lenOffset + dlen
-		strRemain := c.records[dstart+lenOffset : (dend + skip)] // get string
+		dlen, width := readLen(c.records[dend:])
+		skip := width + dlen
+		strRemain := c.records[dstart+width : (dend + skip)] // get string
 		// TODO is this duplicating work by bytes.Equal?
 		if len(strRemain) == len(suffix) {
 			dtest := bytes.Equal(strRemain, suffix)
 			if dtest {
-				found = c.items[suffixCount]
+				found, ok = c.items[suffixCount], true
 
 				// remove
 				c.records = append(
@@ -347,141 +541,206 @@ func (c *compactArray) remove(suffix []byte) (found Byte) {
 	}
 }
 
-var listContainerMax int = 150
-
-func optimize() func(b *testing.B) {
-
-	l := list.New()
-	for i := 0; i < listContainerMax; i++ {
-		l.PushFront(i)
+// Tune finds the burst limit for a kind of container on this machine, by timing searches for
+// suffixes which aren't there in ever bigger containers. The limit is the size at which such a
+// search first takes over 200ns. Timings vary from machine to machine and run to run, so unlike
+// the defaults the result isn't repeatable. Tuning takes a moment, so is best done once at start up:
+//
+//	config := gotree.Tune(gotree.ListContainer)
+//	burst := gotree.NewBurstTree(gotree.WithConfig(config))
+func Tune(kind ContainerKind) BurstConfig {
+	const (
+		target   = 200 * time.Nanosecond
+		maxLimit = 1 << 16
+		misses   = 64
+	)
+	// the same suffixes every time, so only the timings vary
+	r := rand.New(rand.NewSource(1))
+	suffix := func() []byte {
+		b := make([]byte, 4+r.Intn(8))
+		r.Read(b)
+		return b
+	}
+	missing := make([][]byte, misses)
+	for i := range missing {
+		missing[i] = append(suffix(), '!')
 	}
-	origMax := listContainerMax
-	return func(b *testing.B) {
-		b.StopTimer()
-		b.ResetTimer()
-		// add upto new listContainerMax
-		for i := origMax; i < listContainerMax; i++ {
-			l.PushFront(i)
-		}
-		origMax = listContainerMax
-		b.StartTimer()
-		for i := 0; i < b.N; i++ {
-			for e := l.Front(); e != nil; e = e.Next() {
 
+	c := containerMaker[struct{}](kind)()
+	never := &BurstConfig{Container: kind, BurstLimit: maxLimit}
+	size := 0
+	limit := 16
+	for ; limit < maxLimit; limit += limit / 2 {
+		for ; size < limit; size++ {
+			c.insert(suffix(), struct{}{}, never)
+		}
+		// search until there's enough time passed to trust
+		searches := 0
+		start := time.Now()
+		for time.Since(start) < time.Millisecond {
+			for _, m := range missing {
+				c.search(m)
 			}
+			searches += misses
 		}
-
+		if time.Since(start)/time.Duration(searches) > target {
+			break
+		}
+	}
+	if limit > maxLimit {
+		limit = maxLimit
 	}
+	return BurstConfig{Container: kind, BurstLimit: limit}.resolve()
 }
 
-func init() {
-	//TODO Optimize listContainerMax prior to use
-	optimized := false
-	benchFunc := optimize()
-	for !optimized {
-		// we look for a linear search that will take around 200 ns
-		result := testing.Benchmark(benchFunc).NsPerOp()
-		if result > 200 {
-			optimized = true
-		} else {
-			listContainerMax += 50
-			//fmt.Println("result:", result, "size:", listContainerMax)
-		}
+type listContainer[T any] struct {
+	*list.List
+	single    T    // empty byte holder
+	hasSingle bool // whether single holds an item
+	stats     accessStats
+}
+
+func (l *listContainer[T]) size() int {
+	return l.Len()
+}
+
+func (l *listContainer[T]) footprint() (n int) {
+	for e := l.Front(); e != nil; e = e.Next() {
+		key := e.Value.(*listElem[T]).key
+		n += lenWidth(len(key)) + len(key)
 	}
+	return
 }
 
-type listContainer struct {
-	*list.List
-	single Byte // empty byte holder
+func (l *listContainer[T]) counts() *accessStats {
+	return &l.stats
 }
 
-type listElem struct {
+type listElem[T any] struct {
 	key  []byte
-	item Byte
+	item T
 }
-type listElemSlice []listElem
+type listElemSlice[T any] []listElem[T]
 
-func (p listElemSlice) Len() int           { return len(p) }
-func (p listElemSlice) Less(i, j int) bool { return bytes.Compare(p[i].key, p[j].key) <= 0 }
-func (p listElemSlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p listElemSlice[T]) Len() int           { return len(p) }
+func (p listElemSlice[T]) Less(i, j int) bool { return bytes.Compare(p[i].key, p[j].key) <= 0 }
+func (p listElemSlice[T]) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 
-func (l *listContainer) search(suffix []byte) (found Byte) {
+func (l *listContainer[T]) search(suffix []byte) (found T, ok bool) {
 	// take care of empty string case
 	if len(suffix) == 0 {
-		return l.single
+		return l.single, l.hasSingle
 	}
 	for e := l.Front(); e != nil; e = e.Next() {
-		if bytes.Equal(suffix, e.Value.(*listElem).key) {
+		if bytes.Equal(suffix, e.Value.(*listElem[T]).key) {
 			l.MoveToFront(e)
-			return e.Value.(*listElem).item
+			return e.Value.(*listElem[T]).item, true
 		}
 	}
 	return
 }
 
-func (l *listContainer) isEmpty() bool {
-	if l.single != nil || l.Len() > 0 {
+func (l *listContainer[T]) longestPrefix(query []byte) (found T, ok bool) {
+	found, ok = l.single, l.hasSingle
+	best := 0
+	for e := l.Front(); e != nil; e = e.Next() {
+		elem := e.Value.(*listElem[T])
+		if len(elem.key) > best && bytes.HasPrefix(query, elem.key) {
+			found, ok, best = elem.item, true, len(elem.key)
+		}
+	}
+	return
+}
+
+func (l *listContainer[T]) isEmpty() bool {
+	if l.hasSingle || l.Len() > 0 {
 		return false
 	}
 	return true
 }
 
-func (l *listContainer) iter(order TravOrder) (fn func() ([]byte, Byte)) {
-	// TODO Sort
+func (l *listContainer[T]) entries() []containerEntry[T] {
+	entries := l.unsorted()
+	sortEntries(entries)
+	return entries
+}
+
+// the entries in list order, the empty suffix first
+func (l *listContainer[T]) unsorted() []containerEntry[T] {
+	entries := make([]containerEntry[T], 0, l.Len()+1)
+	if l.hasSingle {
+		entries = append(entries, containerEntry[T]{[]byte{}, l.single})
+	}
 	for e := l.Front(); e != nil; e = e.Next() {
+		elem := e.Value.(*listElem[T])
+		entries = append(entries, containerEntry[T]{elem.key, elem.item})
 	}
+	return entries
+}
 
-	return
+func (l *listContainer[T]) iter(order TravOrder) (fn func() ([]byte, T, bool)) {
+	if order == AnyOrder {
+		return iterEntries(l.unsorted(), order)
+	}
+	return iterEntries(l.entries(), order)
 }
 
-func (l *listContainer) insert(suffix []byte, item Byte) (old Byte, newParent *accessContainer) {
+func (l *listContainer[T]) insert(suffix []byte, item T, p *BurstConfig) (old T, ok bool, newParent *accessContainer[T]) {
 	if len(suffix) == 0 {
 		// empty string case
-		old = l.single
-		l.single = item
+		old, ok = l.single, l.hasSingle
+		l.single, l.hasSingle = item, true
 		return
 	}
 	// search for previous old entry to return
 	for e := l.Front(); e != nil; e = e.Next() {
-		if bytes.Equal(suffix, e.Value.(*listElem).key) {
+		if elem := e.Value.(*listElem[T]); bytes.Equal(suffix, elem.key) {
 			l.MoveToFront(e)
-			old = e.Value.(*listElem).item
+			old, ok = elem.item, true
+			elem.item = item
 			return
 		}
 	}
-	// not found so add it in
-	l.PushFront(&listElem{suffix, item})
+	// not found so add it in, the suffix is the callers so keep a copy
+	l.PushFront(&listElem[T]{append([]byte{}, suffix...), item})
 
 	// check if we need to burst
-	if l.Len() > containerMax {
+	if p.full(l) {
 		// add more depth to tree
-		newParent = &accessContainer{}
+		newParent = &accessContainer[T]{segment: segmentOf(l.unsorted())}
 		// transfer empty string
-		newParent.single = l.single
+		newParent.single, newParent.hasSingle = l.single, l.hasSingle
 		// transfer the rest
 		for e := l.Front(); e != nil; e = e.Next() {
-			elem := e.Value.(*listElem)
+			elem := e.Value.(*listElem[T])
+			elem.key = elem.key[len(newParent.segment):]
+			if len(elem.key) == 0 {
+				// all of it was the segment
+				newParent.single, newParent.hasSingle = elem.item, true
+				continue
+			}
 			// byte to be removed
 			index := elem.key[0]
 			// remove byte
 			elem.key = elem.key[1:]
 			// if we have not created a new child yet create new child
 			// first check for empty string case
-			if newParent.records[index] == nil {
-				newContainer := &listContainer{list.New(), nil}
+			if newParent.records.get(index) == nil {
+				newContainer := &listContainer[T]{List: list.New()}
 				if len(elem.key) == 0 {
-					newContainer.single = elem.item
+					newContainer.single, newContainer.hasSingle = elem.item, true
 				} else {
 					newContainer.PushBack(elem)
 
 				}
 				// set new child
-				newParent.records[index] = newContainer
+				newParent.records.set(index, newContainer)
 			} else {
+				child := newParent.records.get(index).(*listContainer[T])
 				if len(elem.key) == 0 {
-					newParent.records[index].(*listContainer).single = elem.item
+					child.single, child.hasSingle = elem.item, true
 				} else {
-					newParent.records[index].(*listContainer).PushBack(elem)
+					child.PushBack(elem)
 
 				}
 			}
@@ -495,18 +754,419 @@ func (l *listContainer) insert(suffix []byte, item Byte) (old Byte, newParent *a
 	return
 }
 
-func (l *listContainer) remove(suffix []byte) (old Byte) {
+func (l *listContainer[T]) remove(suffix []byte) (old T, ok bool) {
 	if len(suffix) == 0 {
 		// empty string case
-		old = l.single
-		l.single = nil
+		var zero T
+		old, ok = l.single, l.hasSingle
+		l.single, l.hasSingle = zero, false
 		return
 	}
 	for e := l.Front(); e != nil; e = e.Next() {
-		if bytes.Equal(suffix, e.Value.(*listElem).key) {
-			old = l.Remove(e).(*listElem).item
+		if bytes.Equal(suffix, e.Value.(*listElem[T]).key) {
+			return l.Remove(e).(*listElem[T]).item, true
+		}
+	}
+	return
+}
+
+const (
+	arrayHashSlots = 64 // the most slots an arrayHash has, a power of two
+	arrayHashLoad  = 4  // items a slot holds on average before the slots double
+)
+
+// arrayHash is the container of the HAT-trie. Suffixes are hashed into slots, each of which
+// packs its suffixes into a single length prefixed byte slice as compactArray does. So a
+// search reads a short, contiguous run of memory rather than chasing pointers.
+// The slots start out few, and double as the items grow, so the many small containers
+// left by bursting stay small.
+type arrayHash[T any] struct {
+	single    T
+	hasSingle bool
+	count     int           // items held in the slots
+	slots     []hashSlot[T] // a power of two of them, nil while empty
+	stats     accessStats
+}
+
+func (h *arrayHash[T]) size() int {
+	return h.count
+}
+
+func (h *arrayHash[T]) footprint() (n int) {
+	for i := range h.slots {
+		n += len(h.slots[i].records)
+	}
+	return
+}
+
+// slot is where suffix belongs, nil while there are no slots.
+func (h *arrayHash[T]) slot(suffix []byte) *hashSlot[T] {
+	if len(h.slots) == 0 {
+		return nil
+	}
+	return &h.slots[hashSuffix(suffix)&uint32(len(h.slots)-1)]
+}
+
+// grow doubles the slots, rehashing every suffix into its new slot.
+func (h *arrayHash[T]) grow() {
+	old := h.slots
+	h.slots = make([]hashSlot[T], 2*len(old))
+	h.count = 0
+	for i := range old {
+		s := &old[i]
+		for offset, index := 0, 0; offset < len(s.records); index++ {
+			dlen, width := readLen(s.records[offset:])
+			skip := width + dlen
+			h.add(s.records[offset+width:offset+skip], s.items[index])
+			offset += skip
+		}
+	}
+}
+
+func (h *arrayHash[T]) counts() *accessStats {
+	return &h.stats
+}
+
+type hashSlot[T any] struct {
+	records []byte
+	items   []T
+}
+
+// fnv-1a, folded so the low bits which pick a slot depend upon the high bits too
+func hashSuffix(suffix []byte) uint32 {
+	h := uint32(2166136261)
+	for _, c := range suffix {
+		h ^= uint32(c)
+		h *= 16777619
+	}
+	return h ^ h>>16
+}
+
+// find returns the index of the item held under suffix within the slot, and the offset of its
+// record, or -1 when the suffix is not held.
+func (s *hashSlot[T]) find(suffix []byte) (index, offset int) {
+	for offset < len(s.records) {
+		// compute offsets
+		dlen, width := readLen(s.records[offset:])
+		skip := width + dlen
+		if dlen == len(suffix) && bytes.Equal(s.records[offset+width:offset+skip], suffix) {
 			return
 		}
+		offset += skip
+		index++
+	}
+	return -1, -1
+}
+
+func (h *arrayHash[T]) search(suffix []byte) (found T, ok bool) {
+	// take care of empty string case
+	if len(suffix) == 0 {
+		return h.single, h.hasSingle
+	}
+	s := h.slot(suffix)
+	if s == nil {
+		return
+	}
+	if i, _ := s.find(suffix); i >= 0 {
+		return s.items[i], true
+	}
+	return
+}
+
+func (h *arrayHash[T]) insert(suffix []byte, item T, p *BurstConfig) (old T, ok bool, newParent *accessContainer[T]) {
+	if len(suffix) == 0 {
+		// empty string case
+		old, ok = h.single, h.hasSingle
+		h.single, h.hasSingle = item, true
+		return
+	}
+	if s := h.slot(suffix); s != nil {
+		if i, _ := s.find(suffix); i >= 0 {
+			old, ok = s.items[i], true
+			s.items[i] = item
+			return
+		}
+	}
+	h.add(suffix, item)
+
+	// check if we need to burst
+	if p.full(h) {
+		// add more depth to tree, with the same kind of container below
+		entries := h.unsorted()
+		newParent = &accessContainer[T]{segment: segmentOf(entries)}
+		for _, e := range entries {
+			key := e.key[len(newParent.segment):]
+			if len(key) == 0 {
+				// transfer empty string
+				newParent.single, newParent.hasSingle = e.item, true
+				continue
+			}
+			child, _ := newParent.records.get(key[0]).(*arrayHash[T])
+			if child == nil {
+				child = &arrayHash[T]{}
+				newParent.records.set(key[0], child)
+			}
+			if len(key) == 1 {
+				child.single, child.hasSingle = e.item, true
+			} else {
+				child.add(key[1:], e.item)
+			}
+		}
+	}
+	return
+}
+
+// add appends a suffix not already held to its slot, without any check for bursting.
+func (h *arrayHash[T]) add(suffix []byte, item T) {
+	if h.slots == nil {
+		h.slots = make([]hashSlot[T], 1)
+	}
+	s := h.slot(suffix)
+	s.records = appendLen(s.records, len(suffix))
+	s.records = append(s.records, suffix...)
+	s.items = append(s.items, item)
+	h.count++
+	if h.count > arrayHashLoad*len(h.slots) && len(h.slots) < arrayHashSlots {
+		h.grow()
+	}
+}
+
+func (h *arrayHash[T]) remove(suffix []byte) (old T, ok bool) {
+	if len(suffix) == 0 {
+		// empty string case
+		var zero T
+		old, ok = h.single, h.hasSingle
+		h.single, h.hasSingle = zero, false
+		return
+	}
+	s := h.slot(suffix)
+	if s == nil {
+		return
+	}
+	i, offset := s.find(suffix)
+	if i < 0 {
+		return
+	}
+	old, ok = s.items[i], true
+	s.records = append(s.records[:offset], s.records[offset+lenWidth(len(suffix))+len(suffix):]...)
+	s.items = append(s.items[:i], s.items[i+1:]...)
+	h.count--
+	if h.count == 0 {
+		// start small again
+		h.slots = nil
+	}
+	return
+}
+
+func (h *arrayHash[T]) longestPrefix(query []byte) (found T, ok bool) {
+	found, ok = h.single, h.hasSingle
+	// every prefix of query has a slot of its own to look in, so try the longest first
+	for n := len(query); n > 0; n-- {
+		if found, ok := h.search(query[:n]); ok {
+			return found, ok
+		}
+	}
+	return
+}
+
+func (h *arrayHash[T]) isEmpty() bool {
+	return !h.hasSingle && h.count == 0
+}
+
+func (h *arrayHash[T]) entries() []containerEntry[T] {
+	entries := h.unsorted()
+	sortEntries(entries)
+	return entries
+}
+
+// the entries slot by slot, the empty suffix first
+func (h *arrayHash[T]) unsorted() []containerEntry[T] {
+	entries := make([]containerEntry[T], 0, h.count+1)
+	if h.hasSingle {
+		entries = append(entries, containerEntry[T]{[]byte{}, h.single})
+	}
+	for i := range h.slots {
+		s := &h.slots[i]
+		for offset, index := 0, 0; offset < len(s.records); index++ {
+			dlen, width := readLen(s.records[offset:])
+			skip := width + dlen
+			entries = append(entries, containerEntry[T]{s.records[offset+width : offset+skip], s.items[index]})
+			offset += skip
+		}
+	}
+	return entries
+}
+
+func (h *arrayHash[T]) iter(order TravOrder) (fn func() ([]byte, T, bool)) {
+	if order == AnyOrder {
+		return iterEntries(h.unsorted(), order)
+	}
+	return iterEntries(h.entries(), order)
+}
+
+// sortedArray is a compactArray which keeps its suffixes in order as they are inserted, along with
+// the offset of each one within records. Searching is then a binary search, and ordered iteration
+// needs no sorting.
+type sortedArray[T any] struct {
+	single    T
+	hasSingle bool
+	items     []T
+	offsets   []int // where each record begins
+	// length prefixed, sorted byte strings
+	records []byte
+	stats   accessStats
+}
+
+func (c *sortedArray[T]) size() int {
+	return len(c.items)
+}
+
+func (c *sortedArray[T]) footprint() int {
+	return len(c.records)
+}
+
+func (c *sortedArray[T]) counts() *accessStats {
+	return &c.stats
+}
+
+// the i'th smallest suffix
+func (c *sortedArray[T]) key(i int) []byte {
+	offset := c.offsets[i]
+	dlen, width := readLen(c.records[offset:])
+	return c.records[offset+width : offset+width+dlen]
+}
+
+// index returns the position of suffix, or where it belongs, and whether it is held there.
+func (c *sortedArray[T]) index(suffix []byte) (i int, ok bool) {
+	i = sort.Search(len(c.offsets), func(i int) bool {
+		return bytes.Compare(c.key(i), suffix) >= 0
+	})
+	return i, i < len(c.offsets) && bytes.Equal(c.key(i), suffix)
+}
+
+func (c *sortedArray[T]) search(suffix []byte) (found T, ok bool) {
+	// take care of empty string case
+	if len(suffix) == 0 {
+		return c.single, c.hasSingle
+	}
+	if i, ok := c.index(suffix); ok {
+		return c.items[i], true
 	}
 	return
 }
+
+func (c *sortedArray[T]) insert(suffix []byte, item T, p *BurstConfig) (old T, ok bool, newParent *accessContainer[T]) {
+	if len(suffix) == 0 {
+		// empty string case
+		old, ok = c.single, c.hasSingle
+		c.single, c.hasSingle = item, true
+		return
+	}
+	i, ok := c.index(suffix)
+	if ok {
+		old = c.items[i]
+		c.items[i] = item
+		return
+	}
+	// make room for the record at i, and shift along the offsets after it
+	offset := len(c.records)
+	if i < len(c.offsets) {
+		offset = c.offsets[i]
+	}
+	record := append(appendLen(nil, len(suffix)), suffix...)
+	c.records = slices.Insert(c.records, offset, record...)
+	c.offsets = slices.Insert(c.offsets, i, offset)
+	for j := i + 1; j < len(c.offsets); j++ {
+		c.offsets[j] += len(record)
+	}
+	c.items = slices.Insert(c.items, i, item)
+
+	// check if we need to burst
+	if p.full(c) {
+		// add more depth to tree, with the same kind of container below
+		newParent = &accessContainer[T]{segment: segmentOf(c.entries())}
+		// transfer empty string
+		newParent.single, newParent.hasSingle = c.single, c.hasSingle
+		// the records are in order, so each child can simply be appended to
+		for j := range c.offsets {
+			key := c.key(j)[len(newParent.segment):]
+			if len(key) == 0 {
+				// all of it was the segment
+				newParent.single, newParent.hasSingle = c.items[j], true
+				continue
+			}
+			child, _ := newParent.records.get(key[0]).(*sortedArray[T])
+			if child == nil {
+				child = &sortedArray[T]{}
+				newParent.records.set(key[0], child)
+			}
+			if len(key) == 1 {
+				child.single, child.hasSingle = c.items[j], true
+			} else {
+				child.extend(key[1:], c.items[j])
+			}
+		}
+	}
+	return
+}
+
+// extend appends a suffix greater than any held, without any check for bursting.
+func (c *sortedArray[T]) extend(suffix []byte, item T) {
+	c.offsets = append(c.offsets, len(c.records))
+	c.records = appendLen(c.records, len(suffix))
+	c.records = append(c.records, suffix...)
+	c.items = append(c.items, item)
+}
+
+func (c *sortedArray[T]) remove(suffix []byte) (old T, ok bool) {
+	if len(suffix) == 0 {
+		// empty string case
+		var zero T
+		old, ok = c.single, c.hasSingle
+		c.single, c.hasSingle = zero, false
+		return
+	}
+	i, ok := c.index(suffix)
+	if !ok {
+		return
+	}
+	old = c.items[i]
+	offset, skip := c.offsets[i], lenWidth(len(suffix))+len(suffix)
+	c.records = slices.Delete(c.records, offset, offset+skip)
+	c.offsets = slices.Delete(c.offsets, i, i+1)
+	for j := i; j < len(c.offsets); j++ {
+		c.offsets[j] -= skip
+	}
+	c.items = slices.Delete(c.items, i, i+1)
+	return
+}
+
+func (c *sortedArray[T]) longestPrefix(query []byte) (found T, ok bool) {
+	// try the longest prefix first
+	for n := len(query); n > 0; n-- {
+		if i, held := c.index(query[:n]); held {
+			return c.items[i], true
+		}
+	}
+	return c.single, c.hasSingle
+}
+
+func (c *sortedArray[T]) isEmpty() bool {
+	return !c.hasSingle && len(c.items) == 0
+}
+
+// the entries are always in order, the empty suffix first
+func (c *sortedArray[T]) entries() []containerEntry[T] {
+	entries := make([]containerEntry[T], 0, len(c.items)+1)
+	if c.hasSingle {
+		entries = append(entries, containerEntry[T]{[]byte{}, c.single})
+	}
+	for i, item := range c.items {
+		entries = append(entries, containerEntry[T]{c.key(i), item})
+	}
+	return entries
+}
+
+func (c *sortedArray[T]) iter(order TravOrder) (fn func() ([]byte, T, bool)) {
+	return iterEntries(c.entries(), order)
+}
@@ -0,0 +1,227 @@
+package gotree
+
+import (
+	"fmt"
+	"iter"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// A Pattern is a compiled wildcard or regular expression to be matched against whole keys.
+// A BurstTree runs the pattern over its keys a byte at a time as it goes down the tree, and so passes
+// over any branch as soon as no key beneath it could match, rather than testing every key.
+//
+// Keys are matched byte by byte, with each byte standing for the character of the same value.
+// So '.' and '?' match a single byte, and a regular expression should stick to ASCII.
+type Pattern struct {
+	expr string
+	prog *syntax.Prog
+}
+
+// CompileWildcard compiles a shell style wildcard, where '?' matches any single byte and '*' any run of bytes,
+// including none. Every other byte must match itself, so "c?t" matches "cat" and "cut" while "ab*" matches
+// all the keys beginning with "ab".
+func CompileWildcard(pattern string) *Pattern {
+	var expr strings.Builder
+	expr.WriteString("(?s)")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '?':
+			expr.WriteString(".")
+		case c == '*':
+			expr.WriteString(".*")
+		case c < 0x80:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		default:
+			// each byte of a multi byte character stands alone
+			fmt.Fprintf(&expr, `\x{%02x}`, c)
+		}
+	}
+	p, err := CompileRegexp(expr.String())
+	if err != nil {
+		s := fmt.Sprintf("CompileWildcard produced a bad expression %q: %s", expr.String(), err)
+		panic(s)
+	}
+	p.expr = pattern
+	return p
+}
+
+// CompileRegexp compiles a regular expression in the syntax of the regexp package, which must match
+// the whole of a key, as if it were written between ^ and $. Word boundaries are not supported.
+func CompileRegexp(expr string) (*Pattern, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	for _, inst := range prog.Inst {
+		if inst.Op == syntax.InstEmptyWidth &&
+			syntax.EmptyOp(inst.Arg)&(syntax.EmptyWordBoundary|syntax.EmptyNoWordBoundary) != 0 {
+			return nil, fmt.Errorf("gotree: word boundaries are not supported: %q", expr)
+		}
+	}
+	return &Pattern{expr, prog}, nil
+}
+
+func (p *Pattern) String() string {
+	return p.expr
+}
+
+// Match reports whether key matches the pattern.
+func (p *Pattern) Match(key []byte) bool {
+	state := p.start()
+	for _, c := range key {
+		if state = p.step(state, c); len(state) == 0 {
+			return false
+		}
+	}
+	return p.accepts(state, len(key) == 0)
+}
+
+// A matchState is the set of instructions the pattern could be at, having read some key.
+// It is empty once no continuation of the key can match.
+type matchState []uint32
+
+// the state before reading any of the key
+func (p *Pattern) start() matchState {
+	seen := make([]bool, len(p.prog.Inst))
+	return p.follow(nil, seen, uint32(p.prog.Start), syntax.EmptyBeginText|syntax.EmptyBeginLine)
+}
+
+// follow adds pc to state, along with all that can be reached from it without reading a byte
+// given the empty width assertions which hold. Those which don't may yet hold at the end of the key, so stay.
+func (p *Pattern) follow(state matchState, seen []bool, pc uint32, flags syntax.EmptyOp) matchState {
+	if seen[pc] {
+		return state
+	}
+	seen[pc] = true
+	inst := &p.prog.Inst[pc]
+	switch inst.Op {
+	case syntax.InstAlt, syntax.InstAltMatch:
+		state = p.follow(state, seen, inst.Out, flags)
+		return p.follow(state, seen, inst.Arg, flags)
+	case syntax.InstCapture, syntax.InstNop:
+		return p.follow(state, seen, inst.Out, flags)
+	case syntax.InstEmptyWidth:
+		if syntax.EmptyOp(inst.Arg)&^flags == 0 {
+			return p.follow(state, seen, inst.Out, flags)
+		}
+	case syntax.InstFail:
+		return state
+	}
+	return append(state, pc)
+}
+
+// step reads the byte c.
+func (p *Pattern) step(state matchState, c byte) (next matchState) {
+	seen := make([]bool, len(p.prog.Inst))
+	for _, pc := range state {
+		inst := &p.prog.Inst[pc]
+		ok := false
+		switch inst.Op {
+		case syntax.InstRune, syntax.InstRune1:
+			ok = inst.MatchRune(rune(c))
+		case syntax.InstRuneAny:
+			ok = true
+		case syntax.InstRuneAnyNotNL:
+			ok = c != '\n'
+		}
+		if ok {
+			next = p.follow(next, seen, inst.Out, 0)
+		}
+	}
+	return
+}
+
+// accepts reports whether the key read so far matches, it being empty if atStart.
+func (p *Pattern) accepts(state matchState, atStart bool) bool {
+	flags := syntax.EmptyEndText | syntax.EmptyEndLine
+	if atStart {
+		flags |= syntax.EmptyBeginText | syntax.EmptyBeginLine
+	}
+	seen := make([]bool, len(p.prog.Inst))
+	var final matchState
+	for _, pc := range state {
+		final = p.follow(final, seen, pc, flags)
+	}
+	for _, pc := range final {
+		if p.prog.Inst[pc].Op == syntax.InstMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchIter returns an iterator over the keys and elements of the tree whose keys match p, in the
+// lexicographic order of the keys:
+//
+//	for key := range burst.MatchIter(CompileWildcard("c?t")) {
+//	    fmt.Println(string(key))
+//	}
+//
+// As with All, each key is a newly allocated slice.
+func (burst *BurstTreeOf[T]) MatchIter(p *Pattern) iter.Seq2[[]byte, T] {
+	return func(yield func([]byte, T) bool) {
+		if root, ok := burst.root.(*accessContainer[T]); ok {
+			root.match(p, nil, p.start(), yield)
+		}
+	}
+}
+
+// MatchMap calls f with the key and element of each element whose key matches p, in the
+// lexicographic order of the keys. See MatchIter.
+func (burst *BurstTreeOf[T]) MatchMap(p *Pattern, f func(key []byte, item T)) {
+	for key, item := range burst.MatchIter(p) {
+		f(key, item)
+	}
+}
+
+// match yields the items at and below this access container which match p. Their keys begin
+// with prefix, and state is where p is having read prefix.
+func (a *accessContainer[T]) match(p *Pattern, prefix []byte, state matchState, yield func([]byte, T) bool) bool {
+	for _, c := range a.segment {
+		if state = p.step(state, c); len(state) == 0 {
+			return true
+		}
+		prefix = append(prefix, c)
+	}
+	if a.hasSingle && p.accepts(state, len(prefix) == 0) && !yield(append([]byte{}, prefix...), a.single) {
+		return false
+	}
+	for i, record := range a.records.all() {
+		next := p.step(state, byte(i))
+		if len(next) == 0 {
+			// no key down here can match
+			continue
+		}
+		switch cur := record.(type) {
+		case *accessContainer[T]:
+			if !cur.match(p, append(prefix, byte(i)), next, yield) {
+				return false
+			}
+		case container[T]:
+		Entries:
+			for _, e := range cur.entries() {
+				s := next
+				for _, c := range e.key {
+					if s = p.step(s, c); len(s) == 0 {
+						continue Entries
+					}
+				}
+				if !p.accepts(s, false) {
+					continue
+				}
+				key := make([]byte, 0, len(prefix)+1+len(e.key))
+				key = append(append(append(key, prefix...), byte(i)), e.key...)
+				if !yield(key, e.item) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
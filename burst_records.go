@@ -0,0 +1,125 @@
+package gotree
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// recordsWidth is the number of records an access container could have, one for each byte.
+const recordsWidth = 256
+
+// accessRecords are the records of an access container. Rather than a fixed array of all 256 of them,
+// only those in use are held, densely in byte order along with a bitmap of which bytes they are for,
+// in the manner of the adaptive radix tree. Most access containers have only a few records, and so
+// take a few words rather than 4KB. The records grow and shrink as they are set and removed,
+// and the bitmap finds any of them without a search.
+type accessRecords struct {
+	bitmap [recordsWidth / 64]uint64
+	dense  []interface{} // may be a accessContainer or container
+}
+
+func (r *accessRecords) has(b byte) bool {
+	return r.bitmap[b>>6]&(1<<(b&63)) != 0
+}
+
+// rank is the position within dense of the record for b, were it held.
+func (r *accessRecords) rank(b byte) int {
+	w := int(b >> 6)
+	n := bits.OnesCount64(r.bitmap[w] & (1<<(b&63) - 1))
+	for _, word := range r.bitmap[:w] {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// get returns the record for b, nil if there is none.
+func (r *accessRecords) get(b byte) interface{} {
+	if !r.has(b) {
+		return nil
+	}
+	return r.dense[r.rank(b)]
+}
+
+// set makes v the record for b, a nil v removing it.
+func (r *accessRecords) set(b byte, v interface{}) {
+	i := r.rank(b)
+	switch {
+	case r.has(b) && v != nil:
+		r.dense[i] = v
+	case r.has(b):
+		r.bitmap[b>>6] &^= 1 << (b & 63)
+		r.dense = slices.Delete(r.dense, i, i+1)
+		// give back the memory of a once wide access container
+		if cap(r.dense) > 2*len(r.dense)+4 {
+			r.dense = slices.Clone(r.dense)
+		}
+	case v != nil:
+		r.bitmap[b>>6] |= 1 << (b & 63)
+		r.dense = slices.Insert(r.dense, i, v)
+	}
+}
+
+// len is the number of records held.
+func (r *accessRecords) len() int {
+	return len(r.dense)
+}
+
+// next is the first byte from i on which has a record, or recordsWidth if there is none.
+func (r *accessRecords) next(i int) int {
+	for i < recordsWidth {
+		if word := r.bitmap[i>>6] >> (i & 63); word != 0 {
+			return i + bits.TrailingZeros64(word)
+		}
+		i = i&^63 + 64
+	}
+	return recordsWidth
+}
+
+// prev is the last byte from i back which has a record, or -1 if there is none.
+func (r *accessRecords) prev(i int) int {
+	for i >= 0 {
+		if word := r.bitmap[i>>6] << (63 - i&63); word != 0 {
+			return i - bits.LeadingZeros64(word)
+		}
+		i = i&^63 - 1
+	}
+	return -1
+}
+
+// all returns an iterator over the records held and the bytes they are for, in byte order.
+func (r *accessRecords) all() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		k := 0
+		for w, word := range r.bitmap {
+			for ; word != 0; word &= word - 1 {
+				if !yield(w<<6+bits.TrailingZeros64(word), r.dense[k]) {
+					return
+				}
+				k++
+			}
+		}
+	}
+}
+
+// backward is all in reverse.
+func (r *accessRecords) backward() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		k := len(r.dense) - 1
+		for w := len(r.bitmap) - 1; w >= 0; w-- {
+			for word := r.bitmap[w]; word != 0; k-- {
+				top := 63 - bits.LeadingZeros64(word)
+				if !yield(w<<6+top, r.dense[k]) {
+					return
+				}
+				word &^= 1 << top
+			}
+		}
+	}
+}
+
+// footprint is the number of bytes taken by the records, counting the words of
+// the bitmap and slice header along with the interface values held.
+func (r *accessRecords) footprint() int {
+	return len(r.bitmap)*8 + 3*8 + cap(r.dense)*16
+}
@@ -6,21 +6,22 @@ import (
 	"fmt"
 	"github.com/davecgh/go-spew/spew"
 	"io/ioutil"
+	"iter"
+	"maps"
 	"math/rand"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
 )
 
 func BenchmarkBurstText(b *testing.B) {
 
-	containerMax = 256
 	b.StopTimer()
-	burst := &BurstTree{}
-	content, err := ioutil.ReadFile("misc/testText.txt")
-	if err != nil {
-		panic("Couldn't read in file to benchmark on")
-	}
-	data := strings.Fields(string(content))
+	burst := NewBurstTree(WithBurstLimit(256))
+	data := loadText(b)
 	//fmt.Println(len(data))
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
@@ -31,15 +32,69 @@ func BenchmarkBurstText(b *testing.B) {
 	}
 }
 
-func TestBurstText(t *testing.T) {
+// BenchmarkBurstTextContainers runs the BenchmarkBurstText workload, and then searches for every word,
+// with each kind of container.
+func BenchmarkBurstTextContainers(b *testing.B) {
+	data := loadText(b)
+	for _, kind := range []ContainerKind{CompactArray, ListContainer, ArrayHash, SortedArray} {
+		burst := NewBurstTree(WithContainerKind(kind))
+		b.Run(kind.String()+"/insert", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, e := range data {
+					burst.Insert(exString(e))
+				}
+				burst.Clear()
+			}
+		})
+		for _, e := range data {
+			burst.Insert(exString(e))
+		}
+		b.Run(kind.String()+"/search", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, e := range data {
+					burst.Search(exString(e))
+				}
+			}
+		})
+	}
+}
 
-	containerMax = 100
-	burst := &BurstTree{}
-	content, err := ioutil.ReadFile("misc/testText.txt")
-	if err != nil {
-		panic("Couldn't read in file to benchmark on")
+// BenchmarkBurstSortedLimits sweeps the burst limit of SortedArray, upon which its default rests.
+// Its binary search hardly slows as it grows, while each insertion moves half of the array.
+func BenchmarkBurstSortedLimits(b *testing.B) {
+	r := rand.New(rand.NewSource(int64(3)))
+	data := make([]string, 1<<17)
+	for i := range data {
+		data[i] = fmt.Sprintf("%x", r.Int63())
+	}
+	for _, limit := range []int{64, 128, 256, 512, 1024, 2048, 4096} {
+		burst := NewBurstTree(WithContainerKind(SortedArray), WithBurstLimit(limit))
+		name := fmt.Sprintf("%d", limit)
+		b.Run(name+"/insert", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, e := range data {
+					burst.Insert(exString(e))
+				}
+				burst.Clear()
+			}
+		})
+		for _, e := range data {
+			burst.Insert(exString(e))
+		}
+		b.Run(name+"/search", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, e := range data {
+					burst.Search(exString(e))
+				}
+			}
+		})
 	}
-	data := strings.Fields(string(content))
+}
+
+func TestBurstText(t *testing.T) {
+
+	burst := NewBurstTree(WithBurstLimit(100))
+	data := loadText(t)
 	m := map[string]bool{}
 	for _, e := range data {
 		burst.Insert(exString(e))
@@ -68,10 +123,6 @@ func TestBurstText(t *testing.T) {
 
 var _ = spew.Dump
 
-func init() {
-	containerMax = 1
-}
-
 type exByte struct {
 	id string
 }
@@ -80,54 +131,154 @@ func (this exByte) ToBytes() []byte {
 	return []byte(this.id)
 }
 
+// loadText gives the words of the corpus in misc/testText.txt, repeats and all, in the order they come.
+func loadText(tb testing.TB) []string {
+	content, err := ioutil.ReadFile("misc/testText.txt")
+	if err != nil {
+		tb.Fatalf("Couldn't read in file to test on: %s", err)
+	}
+	return strings.Fields(string(content))
+}
+
+// loadWords gives each word of the corpus once, sorted.
+func loadWords(tb testing.TB) []string {
+	return sortedSet(loadText(tb))
+}
+
+// sortedSet gives each of data once, sorted.
+func sortedSet(data []string) []string {
+	words := slices.Clone(data)
+	sort.Strings(words)
+	return slices.Compact(words)
+}
+
+// sortedKeys gives the keys of m, sorted.
+func sortedKeys(m map[string]bool) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
 func testListContainer(t *testing.T) {
-	containerMax = 4
-	x := &listContainer{list.New(), nil}
-	if old, parent := x.insert([]byte{1}, exByte{"1"}); old != nil || parent != nil {
+	limit := 4
+	x := &listContainer[Byte]{List: list.New()}
+	if _, ok, parent := x.insert([]byte{1}, exByte{"1"}, &BurstConfig{BurstLimit: limit}); ok || parent != nil {
 		t.Errorf("inital insert wrong")
 	}
-	if old, parent := x.insert([]byte{2}, exByte{"2"}); old != nil || parent != nil {
+	if _, ok, parent := x.insert([]byte{2}, exByte{"2"}, &BurstConfig{BurstLimit: limit}); ok || parent != nil {
 		t.Errorf("2 element wrong insert")
 	}
-	if old, parent := x.insert([]byte{3}, exByte{"3"}); old != nil || parent != nil {
+	if _, ok, parent := x.insert([]byte{3}, exByte{"3"}, &BurstConfig{BurstLimit: limit}); ok || parent != nil {
 		t.Errorf("3 element wrong insert")
 	}
 	spew.Dump(x)
 }
 func TestCompactArry(t *testing.T) {
-	containerMax = 10
-	x := &compactArray{}
+	limit := 10
+	x := &compactArray[Byte]{}
 
-	data := rand.Perm(containerMax)
+	data := rand.Perm(limit)
 	for _, a := range data {
 		s := fmt.Sprintf("%d", a)
-		x.insert([]byte{byte(a)}, exByte{s})
+		x.insert([]byte{byte(a)}, exByte{s}, &BurstConfig{BurstLimit: limit})
 	}
-	x.sort(InOrder)
 	spew.Dump(x)
-	for i, a := range x.items {
+	for i, e := range x.entries() {
 		s := fmt.Sprintf("%d", i)
 		b := exByte{s}
 
-		if a != b {
+		if e.item != b || len(e.key) != 1 || e.key[0] != byte(i) {
 			t.Errorf("Wrong order")
 		}
 	}
-	x.sort(RevOrder)
-	for i, a := range x.items {
-		s := fmt.Sprintf("%d", containerMax-i-1)
-		b := exByte{s}
-
-		if a != b {
+	i := 0
+	next := x.iter(InOrder)
+	for key, a, ok := next(); ok; key, a, ok = next() {
+		if a != (exByte{fmt.Sprintf("%d", i)}) || key[0] != byte(i) {
 			t.Errorf("Wrong order")
 		}
+		i++
+	}
+	if i != limit {
+		t.Errorf("Did not traverse all elements missing: %d", limit-i)
+	}
+	next = x.iter(RevOrder)
+	for key, a, ok := next(); ok; key, a, ok = next() {
+		i--
+		if a != (exByte{fmt.Sprintf("%d", i)}) || key[0] != byte(i) {
+			t.Errorf("Wrong reverse order")
+		}
+	}
+	if i != 0 {
+		t.Errorf("Did not traverse all elements missing: %d", i)
+	}
+}
+
+func TestSortedArray(t *testing.T) {
+	limit := 300
+	x := &sortedArray[Byte]{}
+
+	r := rand.New(rand.NewSource(int64(5)))
+	m := map[string]bool{}
+	for len(m) < limit {
+		s := fmt.Sprintf("%x", r.Intn(1<<16))
+		if _, _, newParent := x.insert([]byte(s), exByte{s}, &BurstConfig{BurstLimit: limit}); newParent != nil {
+			t.Errorf("Burst too early")
+		}
+		m[s] = true
+	}
+	words := sortedKeys(m)
+
+	for i, e := range x.entries() {
+		if string(e.key) != words[i] || e.item != (exByte{words[i]}) {
+			t.Errorf("Wrong order Got: %s, Exp: %s", e.key, words[i])
+		}
+	}
+	for _, w := range words {
+		if found, ok := x.search([]byte(w)); !ok || found != (exByte{w}) {
+			t.Errorf("Not Found %s", w)
+		}
+	}
+	if _, ok := x.search([]byte("not hex")); ok {
+		t.Errorf("Found a missing suffix")
+	}
+
+	// remove every other word, and then the rest must still be in order
+	for i := 0; i < len(words); i += 2 {
+		if old, ok := x.remove([]byte(words[i])); !ok || old != (exByte{words[i]}) {
+			t.Errorf("Not Removed %s", words[i])
+		}
+	}
+	for i, e := range x.entries() {
+		if string(e.key) != words[2*i+1] {
+			t.Errorf("Wrong order after removal Got: %s, Exp: %s", e.key, words[2*i+1])
+		}
+	}
+	for i := 0; i < len(words); i += 2 {
+		x.insert([]byte(words[i]), exByte{words[i]}, &BurstConfig{BurstLimit: limit})
 	}
 
+	// one more bursts it, with nothing lost
+	_, _, newParent := x.insert([]byte("g"), exByte{"g"}, &BurstConfig{BurstLimit: limit})
+	if newParent == nil {
+		t.Fatalf("Didn't burst")
+	}
+	words = append(words, "g")
+	burst := &BurstTree{}
+	burst.root = &accessContainer[Byte]{}
+	burst.root.(*accessContainer[Byte]).records.set('k', newParent)
+	i := 0
+	for key := range burst.All(InOrder) {
+		if string(key) != "k"+words[i] {
+			t.Errorf("Wrong order after burst Got: %s, Exp: k%s", key, words[i])
+		}
+		i++
+	}
+	if i != len(words) {
+		t.Errorf("Lost elements in the burst: %d", len(words)-i)
+	}
 }
 
 func TestBurstInsertPrimary(t *testing.T) {
-	containerMax = 1
-	burst := &BurstTree{}
+	burst := NewBurstTree(WithBurstLimit(1))
 	var old Byte
 	old = burst.Insert(nil)
 	if old != nil {
@@ -135,13 +286,19 @@ func TestBurstInsertPrimary(t *testing.T) {
 	}
 	old = burst.Insert(exByte{""})
 	if old != nil {
-		t.Errorf("Should avoid empty string")
+		t.Errorf("Should have held nothing under the empty string")
+	}
+	if old = burst.Insert(exByte{""}); old != (exByte{""}) {
+		t.Errorf("Should have replaced the empty string")
+	}
+	if old = burst.Remove(exByte{""}); old != (exByte{""}) || burst.Size() != 0 {
+		t.Errorf("Should have removed the empty string")
 	}
 	burst.Insert(exByte{"a"})
-	if _, ok := burst.root.(*accessContainer); !ok {
+	if _, ok := burst.root.(*accessContainer[Byte]); !ok {
 		t.Errorf("Didnt not correctly handle nil root")
 	}
-	if _, ok := burst.root.(*accessContainer).records['a'].(container); !ok {
+	if _, ok := burst.root.(*accessContainer[Byte]).records.get('a').(container[Byte]); !ok {
 		t.Errorf("Didn't create new container")
 
 	}
@@ -155,7 +312,7 @@ func TestBurstInsertPrimary(t *testing.T) {
 	}
 	old = burst.Insert(exByte{"aab"})
 	//spew.Dump(burst)
-	if _, ok := burst.root.(*accessContainer).records['a'].(*accessContainer); !ok {
+	if _, ok := burst.root.(*accessContainer[Byte]).records.get('a').(*accessContainer[Byte]); !ok {
 		t.Errorf("Didn't create new accessContainer")
 
 	}
@@ -163,7 +320,7 @@ func TestBurstInsertPrimary(t *testing.T) {
 	if s := burst.Size(); s != 3 {
 		t.Errorf("Size isn't proper")
 	}
-	a2 := burst.root.(*accessContainer).records['a'].(*accessContainer).single
+	a2 := burst.root.(*accessContainer[Byte]).records.get('a').(*accessContainer[Byte]).single
 	a := exByte{"a"}
 	if a2 != a {
 		t.Errorf("Didn't add empty string single record")
@@ -175,13 +332,12 @@ func TestBurstInsertPrimary(t *testing.T) {
 }
 
 func TestBurstSearchPrimary(t *testing.T) {
-	containerMax = 1
-	burst := &BurstTree{}
+	burst := NewBurstTree(WithBurstLimit(1))
 	if check := burst.Search(nil); check != nil {
 		t.Errorf("Should not accept nil")
 	}
 	if check := burst.Search(exByte{""}); check != nil {
-		t.Errorf("Should not accept empty string")
+		t.Errorf("Should not find the empty string in an empty tree")
 	}
 	if check := burst.Search(exByte{"a"}); check != nil {
 		t.Errorf("Did not handle nil root")
@@ -201,8 +357,7 @@ func TestBurstSearchPrimary(t *testing.T) {
 }
 
 func TestBurstInsertSwitch(t *testing.T) {
-	containerMax = 1
-	burst := &BurstTree{}
+	burst := NewBurstTree(WithBurstLimit(1))
 	size := 10
 	for i := 1; i < size+1; i++ {
 		s := fmt.Sprintf("%d", i)
@@ -225,8 +380,7 @@ func TestBurstInsertSwitch(t *testing.T) {
 }
 
 func TestBurstInsertContainerInsert(t *testing.T) {
-	containerMax = 1
-	burst := &BurstTree{}
+	burst := NewBurstTree(WithBurstLimit(1))
 	size := 1000
 	start := 10
 	for i := start; i < size+1; i++ {
@@ -250,8 +404,7 @@ func TestBurstInsertContainerInsert(t *testing.T) {
 }
 
 func TestBurstInsertAndSearchRand(t *testing.T) {
-	containerMax = 1
-	burst := &BurstTree{}
+	burst := NewBurstTree(WithBurstLimit(1))
 	size := 2000
 
 	data := rand.Perm(size)
@@ -266,7 +419,7 @@ func TestBurstInsertAndSearchRand(t *testing.T) {
 		t.Error("True:", size, " Found:", s)
 	}
 
-	if check := burst.root.(*accessContainer).single; check != nil {
+	if check := burst.root.(*accessContainer[Byte]).single; check != nil {
 		t.Error("Should be nil in root record")
 	}
 
@@ -284,14 +437,13 @@ func TestBurstInsertAndSearchRand(t *testing.T) {
 }
 
 func TestBurstRemove(t *testing.T) {
-	containerMax = 1
-	burst := &BurstTree{}
+	burst := NewBurstTree(WithBurstLimit(1))
 
 	if check := burst.Remove(nil); check != nil {
 		t.Errorf("Should not accept nil")
 	}
 	if check := burst.Remove(exByte{""}); check != nil {
-		t.Errorf("Should not accept empty string")
+		t.Errorf("Should not remove the empty string from an empty tree")
 	}
 	if check := burst.Remove(exByte{"a"}); check != nil {
 		t.Errorf("Did not handle nil root")
@@ -314,16 +466,13 @@ func TestBurstRemove(t *testing.T) {
 			t.Errorf("Should not have found something")
 		}
 	}
-	for _, v := range burst.root.(*accessContainer).records {
-		if v != nil {
-			t.Errorf("Should have empty root")
-		}
+	if burst.root != nil {
+		t.Errorf("Should have dropped the empty root")
 	}
 }
 
 func TestBurstIter(t *testing.T) {
-	containerMax = 1
-	burst := &BurstTree{}
+	burst := NewBurstTree(WithBurstLimit(1))
 
 	max := 200
 
@@ -345,3 +494,1542 @@ func TestBurstIter(t *testing.T) {
 		prior = x.ToBytes()
 	}
 }
+
+func TestBurstGeneric(t *testing.T) {
+	burst := NewBurstTreeOf(func(s string) []byte { return []byte(s) }, WithBurstLimit(1))
+	words := []string{"b", "a", "ab", "abc", "abd", "ba", "c"}
+	for _, w := range words {
+		if _, ok := burst.Insert(w); ok {
+			t.Errorf("Nothing should have been replaced")
+		}
+	}
+	if old, ok := burst.Insert("ab"); !ok || old != "ab" {
+		t.Errorf("Should have replaced Got: %s", old)
+	}
+	if s := burst.Size(); s != len(words) {
+		t.Errorf("Size isn't proper")
+	}
+	for _, w := range words {
+		if found, ok := burst.Search(w); !ok || found != w {
+			t.Errorf("Should have found %s Got: %s", w, found)
+		}
+	}
+	if _, ok := burst.Search("abe"); ok {
+		t.Errorf("Should not have found something")
+	}
+
+	expected := []string{"a", "ab", "abc", "abd", "b", "ba", "c"}
+	i := 0
+	for x, ok := burst.IterInit(InOrder); ok; x, ok = burst.Next() {
+		if x != expected[i] {
+			t.Errorf("Wrong Order Got: %s, Exp: %s", x, expected[i])
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Did not traverse all elements missing: %d", len(expected)-i)
+	}
+
+	for _, w := range words {
+		if old, ok := burst.Remove(w); !ok || old != w {
+			t.Errorf("Should be Elem: %s be matched element removed", w)
+		}
+	}
+	if s := burst.Size(); s != 0 {
+		t.Errorf("Size isn't proper")
+	}
+}
+
+func TestBurstCursor(t *testing.T) {
+	burst := NewBurstTree(WithBurstLimit(4))
+	c := burst.Cursor()
+	if c.Next() || c.Prev() || c.Seek(exByte{"a"}) || c.Elem() != nil {
+		t.Errorf("Not minding empty tree")
+	}
+
+	r := rand.New(rand.NewSource(int64(5)))
+	words := []string{}
+	for i := 0; i < 2000; i++ {
+		w := fmt.Sprintf("%x", r.Intn(1<<16))
+		if burst.Search(exByte{w}) == nil {
+			words = append(words, w)
+		}
+		burst.Insert(exByte{w})
+	}
+	sort.Strings(words)
+
+	i := 0
+	for c := burst.Cursor(); c.Next(); i++ {
+		if c.Elem() != (exByte{words[i]}) {
+			t.Errorf("Wrong Order Got: %v, Exp: %s", c.Elem(), words[i])
+		}
+	}
+	if i != len(words) {
+		t.Errorf("Did not traverse all elements missing: %d", len(words)-i)
+	}
+	i = len(words) - 1
+	for c := burst.Cursor(); c.Prev(); i-- {
+		if c.Elem() != (exByte{words[i]}) {
+			t.Errorf("Wrong Order Got: %v, Exp: %s", c.Elem(), words[i])
+		}
+	}
+	if i != -1 {
+		t.Errorf("Did not traverse all elements missing: %d", i+1)
+	}
+
+	for k := 0; k < 500; k++ {
+		q := fmt.Sprintf("%x", r.Intn(1<<16))[:1+r.Intn(3)]
+		pos := sort.SearchStrings(words, q)
+		if found := c.Seek(exByte{q}); found != (pos < len(words)) {
+			t.Errorf("Seek of %s wrong result %v", q, found)
+			continue
+		} else if !found {
+			continue
+		}
+		if c.Elem() != (exByte{words[pos]}) {
+			t.Errorf("Seek of %s Got: %v, Exp: %s", q, c.Elem(), words[pos])
+		}
+		if c.Prev() != (pos > 0) || (pos > 0 && c.Elem() != (exByte{words[pos-1]})) {
+			t.Errorf("Prev after seek of %s Got: %v", q, c.Elem())
+		}
+	}
+}
+
+func TestBurstAll(t *testing.T) {
+	burst := NewBurstTree(WithBurstLimit(4))
+	for range burst.All(InOrder) {
+		t.Errorf("Not minding empty tree")
+	}
+
+	r := rand.New(rand.NewSource(int64(5)))
+	words := []string{}
+	for i := 0; i < 2000; i++ {
+		w := fmt.Sprintf("%x", r.Intn(1<<16))
+		if burst.Search(exByte{w}) == nil {
+			words = append(words, w)
+		}
+		burst.Insert(exByte{w})
+	}
+	sort.Strings(words)
+
+	i := 0
+	for key, x := range burst.All(InOrder) {
+		if string(key) != words[i] || x != (exByte{words[i]}) {
+			t.Errorf("Wrong Order Got: %s %v, Exp: %s", key, x, words[i])
+		}
+		i++
+	}
+	if i != len(words) {
+		t.Errorf("Did not traverse all elements missing: %d", len(words)-i)
+	}
+
+	i = 0
+	for key := range burst.All(InOrder) {
+		if string(key) != words[i] {
+			t.Errorf("Wrong Order Got: %s, Exp: %s", key, words[i])
+		}
+		if i == 10 {
+			break
+		}
+		i++
+	}
+}
+
+func TestBurstOrders(t *testing.T) {
+	burst := NewBurstTree(WithBurstLimit(4))
+	for _, order := range []TravOrder{LevelOrder, AnyOrder, RandOrder} {
+		if x := burst.IterInit(order); x != nil {
+			t.Errorf("%s Not minding empty tree", order)
+		}
+	}
+
+	r := rand.New(rand.NewSource(int64(5)))
+	words := map[Byte]bool{}
+	for i := 0; i < 2000; i++ {
+		w := exByte{fmt.Sprintf("%x", r.Intn(1<<16))}
+		words[w] = true
+		burst.Insert(w)
+	}
+
+	for _, order := range []TravOrder{InOrder, RevOrder, LevelOrder, AnyOrder, RandOrder} {
+		burst.SetRand(rand.New(rand.NewSource(int64(7))))
+		expected := []Byte{}
+		seen := map[Byte]bool{}
+		for x := burst.IterInit(order); x != nil; x = burst.Next() {
+			if seen[x] || !words[x] {
+				t.Errorf("%s Unexpected or repeated element %v", order, x)
+			}
+			seen[x] = true
+			expected = append(expected, x)
+		}
+		if len(seen) != len(words) {
+			t.Errorf("%s Did not traverse all elements missing: %d", order, len(words)-len(seen))
+		}
+
+		burst.SetRand(rand.New(rand.NewSource(int64(7))))
+		i := 0
+		burst.Map(order, func(x Byte) {
+			if i < len(expected) && x != expected[i] {
+				t.Errorf("%s Map and Next disagree Got:%v, Exp: %v", order, x, expected[i])
+			}
+			i++
+		})
+		if i != len(expected) {
+			t.Errorf("%s Map visited %d elements, Exp: %d", order, i, len(expected))
+		}
+
+		burst.SetRand(rand.New(rand.NewSource(int64(7))))
+		i = 0
+		for key, x := range burst.All(order) {
+			if i < len(expected) && x != expected[i] {
+				t.Errorf("%s All and Next disagree Got:%v, Exp: %v", order, x, expected[i])
+			}
+			if !bytes.Equal(key, x.ToBytes()) {
+				t.Errorf("%s All gave key %q for %v", order, key, x)
+			}
+			i++
+		}
+		if i != len(expected) {
+			t.Errorf("%s All visited %d elements, Exp: %d", order, i, len(expected))
+		}
+	}
+}
+
+func TestBurstRevOrder(t *testing.T) {
+	burst := NewBurstTree(WithBurstLimit(4))
+	if x := burst.IterInit(RevOrder); x != nil {
+		t.Errorf("Not minding empty tree")
+	}
+
+	r := rand.New(rand.NewSource(int64(5)))
+	words := []string{}
+	for i := 0; i < 2000; i++ {
+		w := fmt.Sprintf("%x", r.Intn(1<<16))
+		if burst.Search(exByte{w}) == nil {
+			words = append(words, w)
+		}
+		burst.Insert(exByte{w})
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(words)))
+
+	i := 0
+	for x := burst.IterInit(RevOrder); x != nil; i, x = i+1, burst.Next() {
+		if i < len(words) && x != (exByte{words[i]}) {
+			t.Errorf("Wrong Order Got: %v, Exp: %s", x, words[i])
+		}
+	}
+	if i != len(words) {
+		t.Errorf("Did not traverse all elements missing: %d", len(words)-i)
+	}
+	if x := burst.Next(); x != nil {
+		t.Errorf("Didn't avoid a non intialized next call")
+	}
+}
+
+func TestBurstMap(t *testing.T) {
+	burst := NewBurstTree(WithBurstLimit(4))
+	burst.Map(InOrder, func(x Byte) {
+		t.Errorf("Not minding empty tree")
+	})
+
+	r := rand.New(rand.NewSource(int64(5)))
+	for i := 0; i < 2000; i++ {
+		burst.Insert(exByte{fmt.Sprintf("%x", r.Intn(1<<16))})
+	}
+
+	for _, order := range []TravOrder{InOrder, RevOrder, AnyOrder, LevelOrder, RandOrder} {
+		burst.SetRand(rand.New(rand.NewSource(int64(7))))
+		expected := []Byte{}
+		for x := burst.IterInit(order); x != nil; x = burst.Next() {
+			expected = append(expected, x)
+		}
+		if len(expected) != burst.Size() {
+			t.Errorf("%s Did not traverse all elements missing: %d", order, burst.Size()-len(expected))
+		}
+
+		burst.SetRand(rand.New(rand.NewSource(int64(7))))
+		i := 0
+		burst.Map(order, func(x Byte) {
+			if i < len(expected) && x != expected[i] {
+				t.Errorf("%s Map and Next disagree Got:%v, Exp: %v", order, x, expected[i])
+			}
+			i++
+		})
+		if i != len(expected) {
+			t.Errorf("%s Map visited %d elements, Exp: %d", order, i, len(expected))
+		}
+	}
+}
+
+func TestListContainerIter(t *testing.T) {
+	l := &listContainer[Byte]{List: list.New()}
+	words := []string{"", "b", "ab", "a", "ba", "c", "aa"}
+	for _, w := range words {
+		l.insert([]byte(w), exByte{w}, &BurstConfig{BurstLimit: 1000})
+	}
+	sort.Strings(words)
+
+	next := l.iter(InOrder)
+	for _, w := range words {
+		if key, x, ok := next(); !ok || string(key) != w || x != (exByte{w}) {
+			t.Errorf("Wrong Order Got: %s %v, Exp: %s", key, x, w)
+		}
+	}
+	if _, _, ok := next(); ok {
+		t.Errorf("Iterated past the end")
+	}
+
+	next = l.iter(RevOrder)
+	for i := len(words) - 1; i >= 0; i-- {
+		if key, _, ok := next(); !ok || string(key) != words[i] {
+			t.Errorf("Wrong Order Got: %s, Exp: %s", key, words[i])
+		}
+	}
+
+	seen := 0
+	next = l.iter(AnyOrder)
+	for _, _, ok := next(); ok; _, _, ok = next() {
+		seen++
+	}
+	if seen != len(words) {
+		t.Errorf("Did not traverse all elements missing: %d", len(words)-seen)
+	}
+}
+
+func TestBurstKeys(t *testing.T) {
+	burst := NewBurstTree(WithBurstLimit(4))
+	if burst.IterInit(InOrder); burst.Key() != nil {
+		t.Errorf("Not minding empty tree")
+	}
+
+	r := rand.New(rand.NewSource(int64(5)))
+	for i := 0; i < 2000; i++ {
+		burst.Insert(exByte{fmt.Sprintf("%x", r.Intn(1<<16))})
+	}
+
+	for _, order := range []TravOrder{InOrder, RevOrder, AnyOrder, LevelOrder, RandOrder} {
+		count := 0
+		for x := burst.IterInit(order); x != nil; x = burst.Next() {
+			if !bytes.Equal(burst.Key(), x.ToBytes()) {
+				t.Errorf("%s Wrong key Got: %s, Exp: %s", order, burst.Key(), x.ToBytes())
+			}
+			count++
+		}
+		if count != burst.Size() {
+			t.Errorf("%s Did not traverse all elements missing: %d", order, burst.Size()-count)
+		}
+		if burst.Key() != nil {
+			t.Errorf("%s Key outlived the iteration", order)
+		}
+
+		count = 0
+		burst.MapKeys(order, func(key []byte, x Byte) {
+			if !bytes.Equal(key, x.ToBytes()) {
+				t.Errorf("%s Wrong key Got: %s, Exp: %s", order, key, x.ToBytes())
+			}
+			count++
+		})
+		if count != burst.Size() {
+			t.Errorf("%s Did not traverse all elements missing: %d", order, burst.Size()-count)
+		}
+	}
+}
+
+func TestBurstPrefix(t *testing.T) {
+	burst := NewBurstTree(WithBurstLimit(100))
+	for range burst.PrefixIter([]byte("a"), 0) {
+		t.Errorf("Not minding empty tree")
+	}
+	data := loadText(t)
+	for _, e := range data {
+		burst.Insert(exString(e))
+	}
+	words := sortedSet(data)
+
+	for _, prefix := range []string{"", "t", "th", "the", "there", "Shake", "zzzz", "a"} {
+		expected := []string{}
+		for _, w := range words {
+			if strings.HasPrefix(w, prefix) {
+				expected = append(expected, w)
+			}
+		}
+		i := 0
+		for key, x := range burst.PrefixIter([]byte(prefix), 0) {
+			if i >= len(expected) || string(key) != expected[i] || x != exString(expected[i]) {
+				t.Errorf("%q Wrong match Got: %s %v", prefix, key, x)
+				break
+			}
+			i++
+		}
+		if i != len(expected) {
+			t.Errorf("%q Found %d matches, Exp: %d", prefix, i, len(expected))
+		}
+
+		// only the first few
+		limit := 3
+		i = 0
+		burst.PrefixMap([]byte(prefix), limit, func(key []byte, x Byte) {
+			if i >= len(expected) || string(key) != expected[i] {
+				t.Errorf("%q Wrong match Got: %s", prefix, key)
+			}
+			i++
+		})
+		if i > limit || i < limit && i != len(expected) {
+			t.Errorf("%q Found %d matches with limit %d, Exp: %d", prefix, i, limit, len(expected))
+		}
+	}
+}
+
+func TestBurstLongestPrefix(t *testing.T) {
+	burst := &BurstTree{}
+	if x := burst.LongestPrefix([]byte("a")); x != nil {
+		t.Errorf("Not minding empty tree")
+	}
+
+	routes := []string{"a", "ab", "abcd", "b", "bcde", "bcdefgh", "c1", "c12", "c123x"}
+	queries := map[string]string{
+		"a": "a", "abc": "ab", "abcd": "abcd", "abcdef": "abcd",
+		"bcd": "b", "bcdefg": "bcde", "bcdefghij": "bcdefgh",
+		"c": "", "c1": "c1", "c1234": "c12", "c123x": "c123x", "d": "", "": "",
+	}
+	// with containers which never burst, then ones which always do
+	for _, max := range []int{1000, 1} {
+		burst = NewBurstTree(WithBurstLimit(max))
+		for _, r := range routes {
+			burst.Insert(exByte{r})
+		}
+		for q, exp := range queries {
+			x := burst.LongestPrefix([]byte(q))
+			if exp == "" && x != nil || exp != "" && x != (exByte{exp}) {
+				t.Errorf("%d Wrong match for %q Got: %v, Exp: %q", max, q, x, exp)
+			}
+		}
+	}
+
+	l := &listContainer[Byte]{List: list.New()}
+	for _, r := range routes {
+		l.insert([]byte(r), exByte{r}, &BurstConfig{BurstLimit: 1000})
+	}
+	for q, exp := range queries {
+		x, ok := l.longestPrefix([]byte(q))
+		if exp == "" && ok || exp != "" && x != (exByte{exp}) {
+			t.Errorf("list Wrong match for %q Got: %v, Exp: %q", q, x, exp)
+		}
+	}
+
+	sa := &sortedArray[Byte]{}
+	for _, r := range routes {
+		sa.insert([]byte(r), exByte{r}, &BurstConfig{BurstLimit: 1000})
+	}
+	for q, exp := range queries {
+		x, ok := sa.longestPrefix([]byte(q))
+		if exp == "" && ok || exp != "" && x != (exByte{exp}) {
+			t.Errorf("sorted array Wrong match for %q Got: %v, Exp: %q", q, x, exp)
+		}
+	}
+
+	h := &arrayHash[Byte]{}
+	for _, r := range routes {
+		h.insert([]byte(r), exByte{r}, &BurstConfig{BurstLimit: 1000})
+	}
+	for q, exp := range queries {
+		x, ok := h.longestPrefix([]byte(q))
+		if exp == "" && ok || exp != "" && x != (exByte{exp}) {
+			t.Errorf("array hash Wrong match for %q Got: %v, Exp: %q", q, x, exp)
+		}
+	}
+}
+
+// the textbook dynamic programming edit distance, to check ApproxIter against
+func levenshtein(a, b string) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		diag := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := diag
+			if a[i-1] != b[j-1] {
+				cost++
+			}
+			diag = row[j]
+			if row[j]+1 < cost {
+				cost = row[j] + 1
+			}
+			if row[j-1]+1 < cost {
+				cost = row[j-1] + 1
+			}
+			row[j] = cost
+		}
+	}
+	return row[len(b)]
+}
+
+func TestBurstApprox(t *testing.T) {
+	burst := &BurstTree{}
+	for range burst.ApproxIter([]byte("a"), 2) {
+		t.Errorf("Not minding empty tree")
+	}
+	words := loadWords(t)
+
+	for _, max := range []int{100, 4} {
+		burst = NewBurstTree(WithBurstLimit(max))
+		for _, w := range words {
+			burst.Insert(exString(w))
+		}
+		for _, q := range []string{"the", "thee", "Shakspeare", "lov", "xyzzy", "a", "honourable"} {
+			for k := 0; k <= 2; k++ {
+				expected := []string{}
+				for _, w := range words {
+					if levenshtein(q, w) <= k {
+						expected = append(expected, w)
+					}
+				}
+				i := 0
+				for key, x := range burst.ApproxIter([]byte(q), k) {
+					if i >= len(expected) || string(key) != expected[i] || x != exString(expected[i]) {
+						t.Errorf("%d %q within %d Wrong match Got: %s %v", max, q, k, key, x)
+						break
+					}
+					i++
+				}
+				if i != len(expected) {
+					t.Errorf("%d %q within %d Found %d matches, Exp: %d", max, q, k, i, len(expected))
+				}
+			}
+		}
+	}
+}
+
+func TestBurstMatch(t *testing.T) {
+	burst := &BurstTree{}
+	for range burst.MatchIter(CompileWildcard("*")) {
+		t.Errorf("Not minding empty tree")
+	}
+	for _, w := range []string{"cat", "cut", "cart", "ct", "abc", "ab", "abacus", "b"} {
+		burst.Insert(exString(w))
+	}
+	wildcards := map[string][]string{
+		"c?t":  {"cat", "cut"},
+		"ab*":  {"ab", "abacus", "abc"},
+		"*t":   {"cart", "cat", "ct", "cut"},
+		"c*t":  {"cart", "cat", "ct", "cut"},
+		"?":    {"b"},
+		"????": {"cart"},
+		"x*":   {},
+	}
+	for pattern, expected := range wildcards {
+		found := []string{}
+		burst.MatchMap(CompileWildcard(pattern), func(key []byte, x Byte) {
+			found = append(found, string(key))
+		})
+		if strings.Join(found, " ") != strings.Join(expected, " ") {
+			t.Errorf("%q Wrong matches Got: %v, Exp: %v", pattern, found, expected)
+		}
+	}
+
+	if _, err := CompileRegexp(`\bcat`); err == nil {
+		t.Errorf("Didn't refuse a word boundary")
+	}
+	if _, err := CompileRegexp(`(cat`); err == nil {
+		t.Errorf("Didn't refuse a bad expression")
+	}
+
+	// the regexp package reads keys as UTF-8, where a pattern reads bytes
+	words := slices.DeleteFunc(loadWords(t), func(w string) bool {
+		return strings.IndexFunc(w, func(r rune) bool { return r >= 0x80 }) >= 0
+	})
+
+	exprs := []string{`th(e|ou)`, `[A-Z][a-z]+ed`, `l.*e$`, `^a.*`, `(ab)*`, `x+y?`, `.`, `[^aeiou]*`, `(?i)LOVE.*`, `.*ing`}
+	for _, max := range []int{100, 4} {
+		burst = NewBurstTree(WithBurstLimit(max))
+		for _, w := range words {
+			burst.Insert(exString(w))
+		}
+		for _, expr := range exprs {
+			p, err := CompileRegexp(expr)
+			if err != nil {
+				t.Fatalf("%q %s", expr, err)
+			}
+			re := regexp.MustCompile(`^(?:` + expr + `)$`)
+			expected := []string{}
+			for _, w := range words {
+				if re.MatchString(w) {
+					expected = append(expected, w)
+					if !p.Match([]byte(w)) {
+						t.Errorf("%q Didn't match %q", expr, w)
+					}
+				}
+			}
+			i := 0
+			for key, x := range burst.MatchIter(p) {
+				if i >= len(expected) || string(key) != expected[i] || x != exString(expected[i]) {
+					t.Errorf("%d %q Wrong match Got: %s %v", max, expr, key, x)
+					break
+				}
+				i++
+			}
+			if i != len(expected) {
+				t.Errorf("%d %q Found %d matches, Exp: %d", max, expr, i, len(expected))
+			}
+		}
+	}
+}
+
+func TestBurstRange(t *testing.T) {
+	burst := &BurstTree{}
+	for range burst.RangeIter(nil, nil, Closed) {
+		t.Errorf("Not minding empty tree")
+	}
+	r := rand.New(rand.NewSource(int64(5)))
+	m := map[string]bool{}
+	for i := 0; i < 3000; i++ {
+		m[fmt.Sprintf("%x", r.Intn(1<<r.Intn(20)))] = true
+	}
+	words := sortedKeys(m)
+
+	ranges := [][2]string{{"", "f"}, {"1", "2"}, {"1", "1"}, {"3a", "3a5"}, {"a", "ab"}, {"f", "ff"}, {"0", "g"}, {"b5", "b50"}, {"c", "b"}}
+	// some bounds which are stored keys themselves
+	for i := 0; i < 10; i++ {
+		lo, hi := words[r.Intn(len(words))], words[r.Intn(len(words))]
+		ranges = append(ranges, [2]string{lo, hi}, [2]string{lo, lo + "3"})
+	}
+	for _, max := range []int{100, 4} {
+		burst = NewBurstTree(WithBurstLimit(max))
+		for _, w := range words {
+			burst.Insert(exByte{w})
+		}
+		for _, lohi := range ranges {
+			for _, bounds := range []Bounds{ClosedOpen, Closed, Open, OpenClosed} {
+				lo, hi := []byte(lohi[0]), []byte(lohi[1])
+				expected := []string{}
+				for _, w := range words {
+					if bounds.afterLo(compareBytes([]byte(w), lo)) && bounds.beforeHi(compareBytes([]byte(w), hi)) {
+						expected = append(expected, w)
+					}
+				}
+				i := 0
+				burst.RangeMap(lo, hi, bounds, func(key []byte, x Byte) {
+					if i >= len(expected) || string(key) != expected[i] || x != (exByte{expected[i]}) {
+						t.Errorf("%d %q %q %s Wrong element Got: %s %v", max, lo, hi, bounds, key, x)
+					}
+					i++
+				})
+				if i != len(expected) {
+					t.Errorf("%d %q %q %s Found %d elements, Exp: %d", max, lo, hi, bounds, i, len(expected))
+				}
+			}
+		}
+
+		// without an upper bound
+		i := sort.SearchStrings(words, "c")
+		for key := range burst.RangeIter([]byte("c"), nil, Open) {
+			if words[i] == "c" {
+				i++
+			}
+			if i >= len(words) || string(key) != words[i] {
+				t.Errorf("%d Wrong element Got: %s", max, key)
+				break
+			}
+			i++
+		}
+		if i != len(words) {
+			t.Errorf("%d Did not reach the end missing: %d", max, len(words)-i)
+		}
+	}
+}
+
+// a Container as a user might write one
+type mapContainer map[string]Byte
+
+func (m mapContainer) Search(suffix []byte) (found Byte, ok bool) {
+	found, ok = m[string(suffix)]
+	return
+}
+
+func (m mapContainer) Insert(suffix []byte, item Byte) (old Byte, ok bool) {
+	old, ok = m[string(suffix)]
+	m[string(suffix)] = item
+	return
+}
+
+func (m mapContainer) Remove(suffix []byte) (old Byte, ok bool) {
+	old, ok = m[string(suffix)]
+	delete(m, string(suffix))
+	return
+}
+
+func (m mapContainer) Len() int {
+	return len(m)
+}
+
+func (m mapContainer) Entries() []ContainerEntry[Byte] {
+	entries := []ContainerEntry[Byte]{}
+	for k, v := range m {
+		entries = append(entries, ContainerEntry[Byte]{[]byte(k), v})
+	}
+	return entries
+}
+
+func TestBurstContainers(t *testing.T) {
+	data := loadText(t)
+	words := sortedSet(data)
+
+	kinds := map[string]*BurstTree{
+		"default":       NewBurstTree(WithBurstLimit(8)),
+		"compact array": NewBurstTree(WithContainerKind(CompactArray), WithBurstLimit(8)),
+		"list":          NewBurstTree(WithContainerKind(ListContainer), WithBurstLimit(8)),
+		"array hash":    NewBurstTree(WithContainerKind(ArrayHash), WithBurstLimit(8)),
+		"sorted array":  NewBurstTree(WithContainerKind(SortedArray), WithBurstLimit(8)),
+		"custom":        NewBurstTree(WithContainer(func() Container[Byte] { return mapContainer{} }), WithBurstLimit(8)),
+	}
+	for name, burst := range kinds {
+		for _, e := range data {
+			burst.Insert(exString(e))
+		}
+		if burst.Size() != len(words) {
+			t.Errorf("%s Sizes don't match Got: %d, Exp: %d", name, burst.Size(), len(words))
+		}
+		for _, w := range words {
+			if x := burst.Search(exString(w)); x != exString(w) {
+				t.Errorf("%s Not Found %v", name, w)
+			}
+		}
+		if x := burst.Search(exString("notAWordInShakespeare")); x != nil {
+			t.Errorf("%s Found a missing word %v", name, x)
+		}
+		i := 0
+		for key := range burst.All(InOrder) {
+			if i >= len(words) || string(key) != words[i] {
+				t.Errorf("%s Wrong Order Got: %s", name, key)
+				break
+			}
+			i++
+		}
+		if i != len(words) {
+			t.Errorf("%s Did not traverse all elements missing: %d", name, len(words)-i)
+		}
+		for _, w := range words {
+			if x := burst.Remove(exString(w)); x != exString(w) {
+				t.Errorf("%s Not Removed %v", name, w)
+			}
+		}
+		if burst.Size() != 0 {
+			t.Errorf("%s Sizes don't match Got: %d, Exp: 0", name, burst.Size())
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Didn't refuse containers of the wrong type")
+		}
+	}()
+	NewBurstTreeOf[string](nil, WithContainer(func() Container[Byte] { return mapContainer{} }))
+}
+
+func TestBurstConfig(t *testing.T) {
+	zero := &BurstTree{}
+	if zero.Config(); zero.config != (BurstConfig{}) {
+		t.Errorf("Config wrote to the tree Got: %+v", zero.config)
+	}
+	if c := (&BurstTree{}).Config(); c.Container != CompactArray || c.BurstLimit != 256 || c.ByteLimit != 4096 {
+		t.Errorf("Wrong zero value config Got: %+v", c)
+	}
+	if c := NewBurstTree(WithContainerKind(ListContainer)).Config(); c.Container != ListContainer || c.BurstLimit != 150 {
+		t.Errorf("Wrong default config Got: %+v", c)
+	}
+	if c := NewBurstTree(WithBurstLimit(7), WithContainerKind(SortedArray)).Config(); c.Container != SortedArray || c.BurstLimit != 7 {
+		t.Errorf("Wrong config Got: %+v", c)
+	}
+
+	// each tree bursts at its own limit
+	small, big := NewBurstTree(WithBurstLimit(2)), NewBurstTree(WithBurstLimit(3))
+	for _, w := range []string{"aa", "ab", "ac"} {
+		small.Insert(exByte{w})
+		big.Insert(exByte{w})
+	}
+	if _, ok := small.root.(*accessContainer[Byte]).records.get('a').(*accessContainer[Byte]); !ok {
+		t.Errorf("Didn't burst past its limit")
+	}
+	if _, ok := big.root.(*accessContainer[Byte]).records.get('a').(container[Byte]); !ok {
+		t.Errorf("Burst within its limit")
+	}
+
+	// a whole config is taken as it is, and later options change only their own part of it
+	fixed := BurstConfig{Container: ListContainer, Policy: SizePolicy, BurstLimit: 40, ByteLimit: 1000,
+		MinAccesses: 8, MissRatio: 0.25, MergeLimit: 5}
+	if c := NewBurstTree(WithConfig(fixed)).Config(); c != fixed {
+		t.Errorf("Didn't take the config Got: %+v, Exp: %+v", c, fixed)
+	}
+	exp := fixed
+	exp.BurstLimit, exp.Policy = 9, CountPolicy
+	if c := NewBurstTree(WithConfig(fixed), WithBurstLimit(9), WithPolicy(CountPolicy)).Config(); c != exp {
+		t.Errorf("Options didn't override the config Got: %+v, Exp: %+v", c, exp)
+	}
+
+	// tuning times real searches, so is slow and varies from run to run
+	if testing.Short() {
+		return
+	}
+	c := Tune(CompactArray)
+	if c.Container != CompactArray || c.BurstLimit < 16 || c.BurstLimit > 1<<16 {
+		t.Errorf("Tuned to a strange config %+v", c)
+	}
+	if burst := NewBurstTree(WithConfig(c)); burst.Config() != c {
+		t.Errorf("Didn't take the tuned config Got: %+v, Exp: %+v", burst.Config(), c)
+	}
+}
+
+func TestBurstPolicies(t *testing.T) {
+	data := loadText(t)
+
+	policies := map[string]*BurstTree{
+		"count": NewBurstTree(WithPolicy(CountPolicy), WithBurstLimit(32)),
+		"size":  NewBurstTree(WithConfig(BurstConfig{Policy: SizePolicy, ByteLimit: 256})),
+		"ratio": NewBurstTree(WithConfig(BurstConfig{Policy: RatioPolicy, MinAccesses: 16, MissRatio: 0.25})),
+	}
+	stats := map[string]BurstStats{}
+	for name, burst := range policies {
+		m := map[string]bool{}
+		for _, e := range data {
+			burst.Insert(exString(e))
+			m[e] = true
+			// a search for a word not held
+			burst.Search(exString(e + "~"))
+		}
+		for w := range m {
+			if x := burst.Search(exString(w)); x != exString(w) {
+				t.Errorf("%s Not Found %s", name, w)
+			}
+		}
+		s := burst.Stats()
+		if s.Items != len(m) || s.Items != burst.Size() {
+			t.Errorf("%s Wrong item count Got: %d, Exp: %d", name, s.Items, len(m))
+		}
+		if s.Bursts == 0 || s.Containers == 0 || s.AccessNodes < 2 || s.MaxDepth < 2 || s.ContainerBytes == 0 {
+			t.Errorf("%s Strange stats %+v", name, s)
+		}
+		stats[name] = s
+		burst.Clear()
+		if s := burst.Stats(); s.Bursts != 0 || s.Items != 0 {
+			t.Errorf("%s Stats not cleared %+v", name, s)
+		}
+	}
+	if stats["count"] == stats["size"] || stats["count"] == stats["ratio"] {
+		t.Errorf("Policies grew the same tree %+v", stats)
+	}
+
+	// the size policy holds to its bytes, and the count policy to its items
+	bytes, count := NewBurstTree(WithConfig(BurstConfig{Policy: SizePolicy, ByteLimit: 64})), NewBurstTree(WithBurstLimit(4))
+	for _, w := range []string{"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa2", "ab"} {
+		bytes.Insert(exByte{w})
+		count.Insert(exByte{w})
+	}
+	if _, ok := bytes.root.(*accessContainer[Byte]).records.get('a').(*accessContainer[Byte]); !ok {
+		t.Errorf("Didn't burst past its byte limit")
+	}
+	if _, ok := count.root.(*accessContainer[Byte]).records.get('a').(container[Byte]); !ok {
+		t.Errorf("Burst within its count limit")
+	}
+
+	// a container which finds all it's asked for is never burst by the ratio policy
+	ratio := NewBurstTree(WithConfig(BurstConfig{Policy: RatioPolicy, MinAccesses: 4}))
+	ratio.Insert(exByte{"a0"})
+	ratio.Insert(exByte{"a1"})
+	for i := 0; i < 1000; i++ {
+		ratio.Search(exByte{fmt.Sprintf("a%d", i%2)})
+	}
+	for i := 2; i < 10; i++ {
+		ratio.Insert(exByte{fmt.Sprintf("a%d", i)})
+	}
+	if s := ratio.Stats(); s.Bursts != 0 {
+		t.Errorf("Burst a container of hits %+v", s)
+	}
+}
+
+// BenchmarkBurstTextPolicies runs the BenchmarkBurstTextContainers workload under each burst policy,
+// reporting the shape of the tree grown.
+func BenchmarkBurstTextPolicies(b *testing.B) {
+	data := loadText(b)
+	for _, policy := range []BurstPolicy{CountPolicy, SizePolicy, RatioPolicy} {
+		burst := NewBurstTree(WithPolicy(policy))
+		b.Run(policy.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				burst.Clear()
+				for _, e := range data {
+					burst.Insert(exString(e))
+				}
+				for _, e := range data {
+					burst.Search(exString(e))
+				}
+			}
+			s := burst.Stats()
+			b.ReportMetric(float64(s.Containers), "containers")
+			b.ReportMetric(s.MeanContainer(), "items/container")
+			b.ReportMetric(float64(s.MaxDepth), "depth")
+		})
+	}
+}
+
+// checkSegments fails if any access container below a holds no item and only one record,
+// and so should have been merged with it, and returns the number of access containers.
+// It leaves the tree as it is.
+func checkSegments(t *testing.T, a *accessContainer[Byte], root bool) (nodes int) {
+	nodes = 1
+	if !root && !a.hasSingle && a.records.len() == 1 {
+		t.Errorf("Unmerged access container under segment %q", a.segment)
+	}
+	if root && len(a.segment) != 0 {
+		t.Errorf("Root with a segment %q", a.segment)
+	}
+	for _, record := range a.records.all() {
+		if cur, ok := record.(*accessContainer[Byte]); ok {
+			nodes += checkSegments(t, cur, false)
+		}
+	}
+	return
+}
+
+func TestBurstSegments(t *testing.T) {
+	words := []string{}
+	for _, site := range []string{"http://example.com/", "https://example.com/", "http://example.org/"} {
+		for _, dir := range []string{"docs/reference/", "docs/reference/api/", "src/internal/"} {
+			for i := 0; i < 30; i++ {
+				words = append(words, fmt.Sprintf("%s%sfile%d", site, dir, i))
+			}
+		}
+		words = append(words, site)
+	}
+	r := rand.New(rand.NewSource(int64(21)))
+	r.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+
+	burst := NewBurstTree(WithBurstLimit(4))
+	for _, w := range words {
+		burst.Insert(exByte{w})
+	}
+	sort.Strings(words)
+	nodes := checkSegments(t, burst.root.(*accessContainer[Byte]), true)
+	if s := burst.Stats(); s.AccessNodes != nodes || nodes > len(words) {
+		t.Errorf("Too many access containers Got: %d, for %d keys", nodes, len(words))
+	}
+	if h := burst.root.(*accessContainer[Byte]).records.get('h').(*accessContainer[Byte]); string(h.segment) != "ttp" {
+		t.Errorf("Wrong segment Got: %q, Exp: %q", h.segment, "ttp")
+	}
+
+	check := func(words []string) {
+		for _, w := range words {
+			if x := burst.Search(exByte{w}); x == nil || string(x.ToBytes()) != w {
+				t.Errorf("Not Found %s", w)
+			}
+			if x := burst.Search(exByte{w + "/"}); x != nil {
+				t.Errorf("Found %s/", w)
+			}
+			if x := burst.Search(exByte{w[:len(w)-1] + "~"}); x != nil {
+				t.Errorf("Found a key leaving a segment %s", w)
+			}
+		}
+		i := 0
+		for key := range burst.All(InOrder) {
+			if i >= len(words) || string(key) != words[i] {
+				t.Fatalf("Wrong key in order Got: %s", key)
+			}
+			i++
+		}
+		i = len(words)
+		for x := burst.IterInit(RevOrder); x != nil; x = burst.Next() {
+			i--
+			if string(burst.Key()) != words[i] || string(x.ToBytes()) != words[i] {
+				t.Fatalf("Wrong reverse key Got: %s, Exp: %s", burst.Key(), words[i])
+			}
+		}
+		burst.MapKeys(LevelOrder, func(key []byte, x Byte) {
+			if string(key) != string(x.ToBytes()) {
+				t.Errorf("Wrong level order key Got: %s, Exp: %s", key, x.ToBytes())
+			}
+		})
+		if i != 0 {
+			t.Errorf("Did not traverse all elements missing: %d", i)
+		}
+		for _, prefix := range []string{"http://example.com/docs/ref", "https://", "http://example.", "http://example.com/", "ftp://", ""} {
+			exp := []string{}
+			for _, w := range words {
+				if strings.HasPrefix(w, prefix) {
+					exp = append(exp, w)
+				}
+			}
+			got := []string{}
+			for key := range burst.PrefixIter([]byte(prefix), 0) {
+				got = append(got, string(key))
+			}
+			if strings.Join(got, " ") != strings.Join(exp, " ") {
+				t.Errorf("Wrong prefix %q Got: %d keys, Exp: %d", prefix, len(got), len(exp))
+			}
+		}
+		lo, hi := []byte("http://example.com/docs/reference/api/file2"), []byte("http://example.org/docs")
+		exp := []string{}
+		for _, w := range words {
+			if w >= string(lo) && w < string(hi) {
+				exp = append(exp, w)
+			}
+		}
+		got := []string{}
+		for key := range burst.RangeIter(lo, hi, ClosedOpen) {
+			got = append(got, string(key))
+		}
+		if strings.Join(got, " ") != strings.Join(exp, " ") {
+			t.Errorf("Wrong range Got: %d keys, Exp: %d", len(got), len(exp))
+		}
+		cursor := burst.Cursor()
+		for _, query := range []string{"http://example.com/docs/reference/api/file2", "http://example.com/docs/zzz", "http://example.com/d", "http://a", "i"} {
+			pos := sort.SearchStrings(words, query)
+			if !cursor.Seek(exByte{query}) {
+				if pos != len(words) {
+					t.Errorf("Seek %s missed %s", query, words[pos])
+				}
+				continue
+			}
+			if x := cursor.Elem(); pos == len(words) || string(x.ToBytes()) != words[pos] {
+				t.Errorf("Seek %s Got: %s", query, x.ToBytes())
+			}
+		}
+		if x := burst.LongestPrefix([]byte("https://example.com/?q=1")); x == nil || string(x.ToBytes()) != "https://example.com/" {
+			t.Errorf("Wrong longest prefix Got: %v", x)
+		}
+		p, query := CompileWildcard("http*/src/*file1?"), "http://example.com/src/internal/fle1"
+		matches, near := 0, 0
+		for _, w := range words {
+			if p.Match([]byte(w)) {
+				matches++
+			}
+			if levenshtein(query, w) <= 1 {
+				near++
+			}
+		}
+		n := 0
+		for range burst.MatchIter(p) {
+			n++
+		}
+		if n != matches {
+			t.Errorf("Wrong number of matches Got: %d, Exp: %d", n, matches)
+		}
+		n = 0
+		for range burst.ApproxIter([]byte(query), 1) {
+			n++
+		}
+		if n != near {
+			t.Errorf("Wrong number of approximate matches Got: %d, Exp: %d", n, near)
+		}
+	}
+	check(words)
+
+	// removing all but a few merges the access containers back together
+	kept := []string{}
+	for _, w := range words {
+		if strings.HasSuffix(w, "/") || strings.HasSuffix(w, "1") {
+			kept = append(kept, w)
+			continue
+		}
+		if x := burst.Remove(exByte{w}); x == nil {
+			t.Errorf("Not Removed %s", w)
+		}
+	}
+	checkSegments(t, burst.root.(*accessContainer[Byte]), true)
+	check(kept)
+	for _, w := range kept {
+		burst.Remove(exByte{w})
+	}
+	if burst.root != nil || burst.Size() != 0 {
+		t.Errorf("Left behind %d items", burst.Size())
+	}
+}
+
+func TestAccessRecords(t *testing.T) {
+	r := rand.New(rand.NewSource(int64(22)))
+	var records accessRecords
+	var exp [recordsWidth]interface{}
+	for round := 0; round < 5000; round++ {
+		b := byte(r.Intn(recordsWidth))
+		// grow for the first half, then shrink
+		if r.Intn(5000) > round {
+			records.set(b, int(b))
+			exp[b] = int(b)
+		} else {
+			records.set(b, nil)
+			exp[b] = nil
+		}
+		i := r.Intn(recordsWidth)
+		if v := records.get(byte(i)); v != exp[i] {
+			t.Fatalf("Wrong record for %d Got: %v, Exp: %v", i, v, exp[i])
+		}
+		next, prev := recordsWidth, -1
+		for j := i; j < recordsWidth; j++ {
+			if exp[j] != nil {
+				next = j
+				break
+			}
+		}
+		for j := i; j >= 0; j-- {
+			if exp[j] != nil {
+				prev = j
+				break
+			}
+		}
+		if records.next(i) != next || records.prev(i) != prev {
+			t.Fatalf("Wrong neighbours of %d Got: %d %d, Exp: %d %d", i, records.next(i), records.prev(i), next, prev)
+		}
+	}
+	held := []int{}
+	for i, v := range exp {
+		if v != nil {
+			held = append(held, i)
+		}
+	}
+	if records.len() != len(held) || cap(records.dense) > 2*len(held)+4 {
+		t.Errorf("Wrong size Got: %d (cap %d), Exp: %d", records.len(), cap(records.dense), len(held))
+	}
+	k := 0
+	for i, v := range records.all() {
+		if i != held[k] || v != exp[i] {
+			t.Fatalf("Wrong record in order Got: %d, Exp: %d", i, held[k])
+		}
+		k++
+	}
+	for i, v := range records.backward() {
+		k--
+		if i != held[k] || v != exp[i] {
+			t.Fatalf("Wrong record in reverse Got: %d, Exp: %d", i, held[k])
+		}
+	}
+	if k != 0 {
+		t.Errorf("Did not traverse all records missing: %d", k)
+	}
+}
+
+// TestBurstMemory reports the memory a tree takes on the Shakespeare corpus. The access containers are
+// set against what the fixed array of 256 records each once took, and the whole tree against the heap,
+// though only the former is checked, the heap being at the mercy of the runtime.
+func TestBurstMemory(t *testing.T) {
+	data := loadText(t)
+	rows := []struct {
+		kind  ContainerKind
+		limit int
+	}{{CompactArray, 4}, {CompactArray, 32}, {CompactArray, 256}, {ArrayHash, 4}, {ArrayHash, 32}, {ArrayHash, 1024}}
+	var before, after runtime.MemStats
+	for _, row := range rows {
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		burst := NewBurstTree(WithContainerKind(row.kind), WithBurstLimit(row.limit))
+		for _, e := range data {
+			burst.Insert(exString(e))
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		heap := int(after.HeapAlloc) - int(before.HeapAlloc)
+
+		s := burst.Stats()
+		fixed := s.AccessNodes * recordsWidth * 16
+		t.Logf("%s, burst limit %d: %d access containers take %d bytes, %d with fixed records, %.1f%% saved",
+			row.kind, row.limit, s.AccessNodes, s.AccessBytes, fixed, 100-100*float64(s.AccessBytes)/float64(fixed))
+		t.Logf("%s, burst limit %d: %d items in %d containers, %d bytes of heap, %d a container",
+			row.kind, row.limit, s.Items, s.Containers, heap, heap/s.Containers)
+		if s.AccessBytes*2 > fixed {
+			t.Errorf("%s Access containers barely shrunk Got: %d bytes, Fixed: %d", row.kind, s.AccessBytes, fixed)
+		}
+		// an array hash only has as many slots as its items need, so a small one stays small
+		var check func(a *accessContainer[Byte])
+		check = func(a *accessContainer[Byte]) {
+			for _, record := range a.records.all() {
+				switch cur := record.(type) {
+				case *accessContainer[Byte]:
+					check(cur)
+				case *arrayHash[Byte]:
+					if n := len(cur.slots); n > arrayHashSlots || n > 1 && cur.count <= arrayHashLoad*n/2 {
+						t.Errorf("%s Too many slots Got: %d for %d items", row.kind, n, cur.count)
+					}
+				}
+			}
+		}
+		check(burst.root.(*accessContainer[Byte]))
+		runtime.KeepAlive(burst)
+	}
+}
+
+// checkMerged fails if any subtrie below a is small enough to have been merged into a container.
+func checkMerged(t *testing.T, burst *BurstTree, a *accessContainer[Byte], limit int) {
+	for _, record := range a.records.all() {
+		if cur, ok := record.(*accessContainer[Byte]); ok {
+			if burst.underfull(cur, limit) {
+				t.Errorf("Unmerged subtrie under segment %q", cur.segment)
+			}
+			checkMerged(t, burst, cur, limit)
+		}
+	}
+}
+
+func TestBurstCompact(t *testing.T) {
+	words := loadWords(t)
+
+	trees := map[string]*BurstTree{
+		"remove":       NewBurstTree(WithBurstLimit(16)),
+		"array hash":   NewBurstTree(WithContainerKind(ArrayHash), WithBurstLimit(16)),
+		"custom":       NewBurstTree(WithContainer(func() Container[Byte] { return mapContainer{} }), WithBurstLimit(16)),
+		"size":         NewBurstTree(WithConfig(BurstConfig{Policy: SizePolicy, ByteLimit: 128})),
+		"compact only": NewBurstTree(WithConfig(BurstConfig{BurstLimit: 16, MergeLimit: -1})),
+	}
+	for name, burst := range trees {
+		for _, w := range words {
+			burst.Insert(exByte{w})
+		}
+		before := burst.Stats()
+		// keep one word in twenty
+		kept := []string{}
+		for i, w := range words {
+			if i%20 == 0 {
+				kept = append(kept, w)
+			} else if x := burst.Remove(exByte{w}); x == nil {
+				t.Errorf("%s Not Removed %s", name, w)
+			}
+		}
+		if name == "compact only" {
+			if s := burst.Stats(); s.Merges != 0 {
+				t.Errorf("%s Merged within Remove %+v", name, s)
+			}
+			burst.Compact()
+		}
+		after := burst.Stats()
+		if after.Merges == 0 || after.AccessNodes >= before.AccessNodes || after.Items != len(kept) {
+			t.Errorf("%s Didn't compact Before: %+v, After: %+v", name, before, after)
+		}
+		limit := burst.Config().MergeLimit
+		if limit < 0 {
+			limit = burst.Config().defaultMerge()
+		}
+		checkMerged(t, burst, burst.root.(*accessContainer[Byte]), limit)
+		checkSegments(t, burst.root.(*accessContainer[Byte]), true)
+
+		i := 0
+		for key := range burst.All(InOrder) {
+			if i >= len(kept) || string(key) != kept[i] {
+				t.Fatalf("%s Wrong key in order Got: %s", name, key)
+			}
+			i++
+		}
+		if i != len(kept) {
+			t.Errorf("%s Did not traverse all elements missing: %d", name, len(kept)-i)
+		}
+		for _, w := range kept {
+			if x := burst.Search(exByte{w}); x == nil || string(x.ToBytes()) != w {
+				t.Errorf("%s Not Found %s", name, w)
+			}
+		}
+		// compacting again changes nothing
+		burst.Compact()
+		if again := burst.Stats(); again != after {
+			t.Errorf("%s Compacted twice Got: %+v, Exp: %+v", name, again, after)
+		}
+		// and it still grows as before
+		for _, w := range words {
+			burst.Insert(exByte{w})
+		}
+		if burst.Size() != len(words) {
+			t.Errorf("%s Wrong size after regrowing Got: %d, Exp: %d", name, burst.Size(), len(words))
+		}
+	}
+
+	// a subtrie burst by misses isn't merged back by a removal, for it would only burst again
+	ratio := NewBurstTree(WithConfig(BurstConfig{Policy: RatioPolicy, MinAccesses: 4}))
+	for i := 0; i < 10; i++ {
+		ratio.Insert(exByte{fmt.Sprintf("a%d", i)})
+	}
+	if _, ok := ratio.root.(*accessContainer[Byte]).records.get('a').(*accessContainer[Byte]); !ok {
+		t.Fatalf("Didn't burst by misses")
+	}
+	ratio.Remove(exByte{"a0"})
+	if _, ok := ratio.root.(*accessContainer[Byte]).records.get('a').(*accessContainer[Byte]); !ok || ratio.Stats().Merges != 0 {
+		t.Errorf("Merged a subtrie burst by misses %+v", ratio.Stats())
+	}
+	// until it is down to its last item
+	for i := 1; i < 9; i++ {
+		ratio.Remove(exByte{fmt.Sprintf("a%d", i)})
+	}
+	if _, ok := ratio.root.(*accessContainer[Byte]).records.get('a').(container[Byte]); !ok || ratio.Size() != 1 {
+		t.Errorf("Didn't merge the last item %+v", ratio.Stats())
+	}
+
+	// under SizePolicy a merge is weighed by the suffixes of the container it makes, which are longer
+	// than those they came from, so putting back a key just removed doesn't burst it again
+	size := NewBurstTree(WithConfig(BurstConfig{Policy: SizePolicy, ByteLimit: 64}))
+	long := "a" + strings.Repeat("x", 30)
+	for i := 0; i < 20; i++ {
+		size.Insert(exByte{fmt.Sprintf("%s%02d", long, i)})
+	}
+	for i := 19; i > 0; i-- {
+		w := exByte{fmt.Sprintf("%s%02d", long, i)}
+		size.Remove(w)
+		before := size.Stats()
+		size.Insert(w)
+		if after := size.Stats(); after.Bursts != before.Bursts {
+			t.Errorf("Burst again after removing %s Before: %+v, After: %+v", w.id, before, after)
+		}
+		size.Remove(w)
+	}
+	if x := size.Search(exByte{long + "00"}); x == nil || size.Size() != 1 {
+		t.Errorf("Lost the last item %+v", size.Stats())
+	}
+
+	// a custom container's empty suffix is weighed just as the other containers weigh theirs
+	custom := &customContainer[Byte]{Container: mapContainer{}}
+	custom.Insert(nil, exByte{""})
+	custom.Insert([]byte("a"), exByte{"a"})
+	if custom.size() != 1 || custom.footprint() != 2 {
+		t.Errorf("Counted the empty suffix Size: %d, Footprint: %d", custom.size(), custom.footprint())
+	}
+
+	// a non positive MergeLimit merges nothing within Remove, segments included
+	none := NewBurstTree(WithConfig(BurstConfig{BurstLimit: 4, MergeLimit: -1}))
+	for _, w := range []string{"abc0", "abc1", "abc2", "abc3", "abc4", "abd0"} {
+		none.Insert(exByte{w})
+	}
+	none.Remove(exByte{"abd0"})
+	if s := none.Stats(); s.Merges != 0 || s.AccessNodes != 3 {
+		t.Errorf("Merged within Remove %+v", s)
+	}
+	none.Compact()
+	if s := none.Stats(); s.AccessNodes != 2 {
+		t.Errorf("Didn't merge the segment in Compact %+v", s)
+	}
+}
+
+func TestBurstLongKeys(t *testing.T) {
+	r := rand.New(rand.NewSource(int64(24)))
+	lengths := []int{2, 127, 128, 255, 256, 16383, 16384, 32767, 32768, 32769, 65535, 65536, 65537, 1 << 20}
+	keys := []string{}
+	for _, n := range lengths {
+		long := make([]byte, n)
+		for i := range long {
+			long[i] = 'a' + byte(r.Intn(4))
+		}
+		// along with others which only differ at the end, and a shorter one
+		for _, c := range "xyz" {
+			long[n-1] = byte(c)
+			keys = append(keys, string(long))
+		}
+		keys = append(keys, string(long[:n-1]))
+	}
+	m := map[string]bool{}
+	for _, k := range keys {
+		m[k] = true
+	}
+	keys = keys[:0]
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, kind := range []ContainerKind{CompactArray, ListContainer, ArrayHash, SortedArray} {
+		for _, limit := range []int{4, 1000} {
+			burst := NewBurstTree(WithContainerKind(kind), WithBurstLimit(limit))
+			for _, k := range keys {
+				burst.Insert(exByte{k})
+			}
+			if burst.Size() != len(keys) {
+				t.Errorf("%s Wrong size Got: %d, Exp: %d", kind, burst.Size(), len(keys))
+			}
+			for _, k := range keys {
+				if x := burst.Search(exByte{k}); x == nil || string(x.ToBytes()) != k {
+					t.Errorf("%s Not Found key of length %d", kind, len(k))
+				}
+			}
+			i := 0
+			for key := range burst.All(InOrder) {
+				if i >= len(keys) || string(key) != keys[i] {
+					t.Fatalf("%s Wrong key in order of length %d", kind, len(key))
+				}
+				i++
+			}
+			for _, k := range keys {
+				if x := burst.Remove(exByte{k}); x == nil || string(x.ToBytes()) != k {
+					t.Errorf("%s Not Removed key of length %d", kind, len(k))
+				}
+			}
+			if burst.Size() != 0 {
+				t.Errorf("%s Sizes don't match Got: %d, Exp: 0", kind, burst.Size())
+			}
+		}
+	}
+
+	// the length prefixes themselves, either side of each extra byte
+	for _, n := range []int{0, 1, 127, 128, 16383, 16384, 1<<21 - 1, 1 << 21} {
+		record := appendLen(nil, n)
+		if dlen, width := readLen(record); dlen != n || width != len(record) || width != lenWidth(n) {
+			t.Errorf("Wrong length prefix for %d Got: %d of width %d, Exp: width %d", n, dlen, width, lenWidth(n))
+		}
+	}
+}
+
+func TestBurstEmptyKey(t *testing.T) {
+	burst := NewBurstTreeOf(func(s string) []byte { return []byte(s) }, WithBurstLimit(2))
+	if _, ok := burst.Search(""); ok {
+		t.Errorf("Found the empty key in an empty tree")
+	}
+	if _, ok := burst.Insert(""); ok {
+		t.Errorf("Nothing should have been replaced")
+	}
+	if x, ok := burst.Search(""); !ok || x != "" || burst.Size() != 1 {
+		t.Errorf("Didn't find the empty key alone Got: %q %v, Size: %d", x, ok, burst.Size())
+	}
+	words := []string{"", "a", "ab", "abc", "b", "ba", "bab", "c"}
+	for _, w := range words[1:] {
+		burst.Insert(w)
+	}
+	if _, ok := burst.Insert(""); !ok || burst.Size() != len(words) {
+		t.Errorf("Didn't replace the empty key Size: %d, Exp: %d", burst.Size(), len(words))
+	}
+
+	// it sorts first, and so comes last in reverse
+	got := []string{}
+	for key, x := range burst.All(InOrder) {
+		if string(key) != x {
+			t.Errorf("Wrong key Got: %q, Exp: %q", key, x)
+		}
+		got = append(got, x)
+	}
+	if strings.Join(got, " ") != strings.Join(words, " ") {
+		t.Errorf("Wrong order Got: %q", got)
+	}
+	got = got[:0]
+	for x, ok := burst.IterInit(RevOrder); ok; x, ok = burst.Next() {
+		if string(burst.Key()) != x {
+			t.Errorf("Wrong key Got: %q, Exp: %q", burst.Key(), x)
+		}
+		got = append(got, x)
+	}
+	if len(got) != len(words) || got[len(got)-1] != "" {
+		t.Errorf("Wrong reverse order Got: %q", got)
+	}
+	got = got[:0]
+	burst.Map(LevelOrder, func(x string) {
+		got = append(got, x)
+	})
+	if len(got) != len(words) || got[0] != "" {
+		t.Errorf("Wrong level order Got: %q", got)
+	}
+	cursor := burst.Cursor()
+	if !cursor.Next() {
+		t.Fatalf("Cursor found nothing")
+	}
+	if x, _ := cursor.Elem(); x != "" {
+		t.Errorf("Cursor didn't start at the empty key Got: %q", x)
+	}
+	if !cursor.Seek("") {
+		t.Fatalf("Seek found nothing")
+	}
+	if x, _ := cursor.Elem(); x != "" || cursor.Prev() {
+		t.Errorf("Seek didn't find the empty key first Got: %q", x)
+	}
+	if !cursor.Seek("a") || !cursor.Prev() {
+		t.Errorf("Couldn't step back from a")
+	} else if x, _ := cursor.Elem(); x != "" {
+		t.Errorf("Stepped back from a to %q", x)
+	}
+
+	// every key begins with it, is a range's lowest and is within an edit of any single byte
+	if key, ok := firstKey(burst.PrefixIter(nil, 1)); !ok || len(key) != 0 {
+		t.Errorf("Prefix didn't begin with the empty key Got: %q", key)
+	}
+	if key, ok := firstKey(burst.RangeIter([]byte{}, []byte("a"), ClosedOpen)); !ok || len(key) != 0 {
+		t.Errorf("Range didn't begin with the empty key Got: %q", key)
+	}
+	if key, ok := firstKey(burst.RangeIter([]byte{}, nil, OpenClosed)); !ok || string(key) != "a" {
+		t.Errorf("Open range didn't skip the empty key Got: %q", key)
+	}
+	if key, ok := firstKey(burst.ApproxIter([]byte("z"), 1)); !ok || len(key) != 0 {
+		t.Errorf("Approx didn't find the empty key Got: %q", key)
+	}
+	if key, ok := firstKey(burst.MatchIter(CompileWildcard("*"))); !ok || len(key) != 0 {
+		t.Errorf("Match didn't find the empty key Got: %q", key)
+	}
+	if x, ok := burst.LongestPrefix([]byte("zebra")); !ok || x != "" {
+		t.Errorf("Wrong longest prefix Got: %q", x)
+	}
+
+	if x, ok := burst.Remove(""); !ok || x != "" || burst.Size() != len(words)-1 {
+		t.Errorf("Didn't remove the empty key Size: %d", burst.Size())
+	}
+	if _, ok := burst.Search(""); ok {
+		t.Errorf("Found the removed empty key")
+	}
+	if _, ok := burst.LongestPrefix([]byte("zebra")); ok {
+		t.Errorf("Found a longest prefix without the empty key")
+	}
+	// it can be the last key left
+	burst.Insert("")
+	for _, w := range words[1:] {
+		burst.Remove(w)
+	}
+	if x, ok := burst.Search(""); !ok || x != "" || burst.Size() != 1 {
+		t.Errorf("Lost the empty key Size: %d", burst.Size())
+	}
+	burst.Remove("")
+	if burst.root != nil || burst.Size() != 0 {
+		t.Errorf("Left behind %d items", burst.Size())
+	}
+
+	// a KeyFunc giving nil for the empty key is just as good
+	blobs := NewBurstTreeOf(func(b []byte) []byte { return b })
+	if _, ok := blobs.Insert(nil); ok || blobs.Size() != 1 {
+		t.Errorf("Didn't insert the nil key Size: %d", blobs.Size())
+	}
+	blobs.Insert([]byte("a"))
+	if _, ok := blobs.Insert([]byte{}); !ok {
+		t.Errorf("Didn't replace the nil key with the empty key")
+	}
+	if _, ok := blobs.Search(nil); !ok {
+		t.Errorf("Didn't find the nil key")
+	}
+	if key, ok := firstKey(blobs.All(InOrder)); !ok || len(key) != 0 {
+		t.Errorf("Nil key didn't sort first Got: %q", key)
+	}
+	if _, ok := blobs.Remove(nil); !ok || blobs.Size() != 1 {
+		t.Errorf("Didn't remove the nil key Size: %d", blobs.Size())
+	}
+
+	// as is a Byte whose ToBytes gives nil, while a nil Byte has no key at all
+	bytesTree := NewBurstTree(WithBurstLimit(4))
+	bytesTree.Insert(exByte{"a"})
+	if x := bytesTree.Insert(nilByte{}); x != nil || bytesTree.Size() != 2 {
+		t.Errorf("Didn't insert the nil key Size: %d", bytesTree.Size())
+	}
+	if x := bytesTree.Search(exByte{""}); x != (nilByte{}) {
+		t.Errorf("Didn't find the nil key as the empty key Got: %v", x)
+	}
+	if x := bytesTree.Insert(nil); x != nil || bytesTree.Size() != 2 {
+		t.Errorf("Inserted a nil Byte Size: %d", bytesTree.Size())
+	}
+	if _, ok := bytesTree.BurstTreeOf.Insert(nil); ok || bytesTree.Size() != 2 {
+		t.Errorf("Inserted a nil Byte Size: %d", bytesTree.Size())
+	}
+	if _, ok := bytesTree.BurstTreeOf.Search(nil); ok {
+		t.Errorf("Found a nil Byte")
+	}
+	if x := bytesTree.Remove(exByte{""}); x != (nilByte{}) || bytesTree.Size() != 1 {
+		t.Errorf("Didn't remove the nil key Size: %d", bytesTree.Size())
+	}
+}
+
+// nilByte is a Byte whose key is nil, and so the empty key.
+type nilByte struct{}
+
+func (nilByte) ToBytes() []byte {
+	return nil
+}
+
+// firstKey is the first key of seq.
+func firstKey[T any](seq iter.Seq2[[]byte, T]) (key []byte, ok bool) {
+	for key := range seq {
+		return key, true
+	}
+	return
+}
@@ -3,18 +3,37 @@
 // A general type that has a total order and a type which can be represented by a byte array.
 package gotree
 
-import ()
+import (
+	"cmp"
+	"iter"
+	"math/rand"
+)
 
 type ByteTree interface {
 	Search(item Byte) (found Byte)
 	Insert(item Byte) (found Byte)
 	Remove(item Byte) (found Byte)
+	LongestPrefix(query []byte) Byte
 	Size() int
 	Clear()
 
+	Cursor() ByteCursor
+	All(order TravOrder) iter.Seq2[[]byte, Byte]
+	PrefixIter(prefix []byte, limit int) iter.Seq2[[]byte, Byte]
+	PrefixMap(prefix []byte, limit int, f func(key []byte, item Byte))
+	RangeIter(lo, hi []byte, bounds Bounds) iter.Seq2[[]byte, Byte]
+	RangeMap(lo, hi []byte, bounds Bounds, f func(key []byte, item Byte))
+	ApproxIter(query []byte, k int) iter.Seq2[[]byte, Byte]
+	ApproxMap(query []byte, k int, f func(key []byte, item Byte))
+	MatchIter(p *Pattern) iter.Seq2[[]byte, Byte]
+	MatchMap(p *Pattern, f func(key []byte, item Byte))
+
 	IterInit(order TravOrder) Byte
 	Next() Byte
+	Key() []byte
 	Map(order TravOrder, f ByteIterFunc)
+	MapKeys(order TravOrder, f func(key []byte, item Byte))
+	SetRand(r *rand.Rand)
 }
 
 type Tree interface {
@@ -29,9 +48,151 @@ type Tree interface {
 	Min() Interface
 	Max() Interface
 
+	// nearest neighbour lookups, nil when no such element exists
+	Floor(item Interface) Interface
+	Ceiling(item Interface) Interface
+	Predecessor(item Interface) Interface
+	Successor(item Interface) Interface
+
+	Cursor() Cursor
+	All(order TravOrder) iter.Seq[Interface]
+
 	IterInit(order TravOrder) Interface
+	IterRangeInit(order TravOrder, lo, hi Interface, bounds Bounds) Interface
 	Next() Interface
 	Map(order TravOrder, f IterFunc)
+	MapRange(order TravOrder, lo, hi Interface, bounds Bounds, f IterFunc)
+	SetRand(r *rand.Rand)
+}
+
+// TreeOf is the type parameterized counterpart of Tree. Rather than relying upon nil to signal
+// a missing element, each lookup also reports whether an element was actually found.
+type TreeOf[T any] interface {
+	Search(item T) (found T, ok bool)
+	Insert(item T) (old T, ok bool)
+	Remove(item T) (old T, ok bool)
+
+	Clear()
+	Size() int
+	Height() int
+
+	Min() (T, bool)
+	Max() (T, bool)
+
+	Floor(item T) (T, bool)
+	Ceiling(item T) (T, bool)
+	Predecessor(item T) (T, bool)
+	Successor(item T) (T, bool)
+
+	Cursor() CursorOf[T]
+	All(order TravOrder) iter.Seq[T]
+
+	IterInit(order TravOrder) (T, bool)
+	IterRangeInit(order TravOrder, lo, hi T, bounds Bounds) (T, bool)
+	Next() (T, bool)
+	Map(order TravOrder, f func(T))
+	MapRange(order TravOrder, lo, hi T, bounds Bounds, f func(T))
+	SetRand(r *rand.Rand)
+}
+
+// ByteTreeOf is the type parameterized counterpart of ByteTree.
+type ByteTreeOf[T any] interface {
+	Search(item T) (found T, ok bool)
+	Insert(item T) (old T, ok bool)
+	Remove(item T) (old T, ok bool)
+	LongestPrefix(query []byte) (found T, ok bool)
+	Size() int
+	Clear()
+
+	Cursor() CursorOf[T]
+	All(order TravOrder) iter.Seq2[[]byte, T]
+	PrefixIter(prefix []byte, limit int) iter.Seq2[[]byte, T]
+	PrefixMap(prefix []byte, limit int, f func(key []byte, item T))
+	RangeIter(lo, hi []byte, bounds Bounds) iter.Seq2[[]byte, T]
+	RangeMap(lo, hi []byte, bounds Bounds, f func(key []byte, item T))
+	ApproxIter(query []byte, k int) iter.Seq2[[]byte, T]
+	ApproxMap(query []byte, k int, f func(key []byte, item T))
+	MatchIter(p *Pattern) iter.Seq2[[]byte, T]
+	MatchMap(p *Pattern, f func(key []byte, item T))
+
+	IterInit(order TravOrder) (T, bool)
+	Next() (T, bool)
+	Key() []byte
+	Map(order TravOrder, f func(T))
+	MapKeys(order TravOrder, f func(key []byte, item T))
+	SetRand(r *rand.Rand)
+}
+
+// A CursorOf is a position within a tree, which walks the elements in order. Unlike IterInit and Next,
+// which keep their state within the tree, a cursor keeps its own, so any number of them may be used at the same time,
+// and from multiple goroutines so long as the tree is not modified. A new cursor is not positioned
+// on any element, Next then moves to the smallest and Prev to the largest. Moving past either end
+// leaves the cursor unpositioned once again. Example:
+//
+//    for c := tree.Cursor(); c.Next(); {
+//        elem, _ := c.Elem()
+//        fmt.Println(elem)
+//    }
+// A cursor is invalidated by any change to the tree.
+type CursorOf[T any] interface {
+	// Next moves to the next larger element, returning false if there is none.
+	Next() bool
+	// Prev moves to the next smaller element, returning false if there is none.
+	Prev() bool
+	// Seek moves to the smallest element greater than or equal to item, returning false if there is none.
+	Seek(item T) bool
+	// Elem returns the element the cursor is positioned on, ok is false when it is not positioned.
+	Elem() (elem T, ok bool)
+}
+
+// A Cursor is the CursorOf used by a Tree, using nil to signal there is no element.
+type Cursor interface {
+	Next() bool
+	Prev() bool
+	Seek(item Interface) bool
+	Elem() Interface
+}
+
+// A ByteCursor is the CursorOf used by a ByteTree, using nil to signal there is no element.
+type ByteCursor interface {
+	Next() bool
+	Prev() bool
+	Seek(item Byte) bool
+	Elem() Byte
+}
+
+// cursor wraps a CursorOf to give a Cursor
+type cursor struct {
+	CursorOf[Interface]
+}
+
+func (c cursor) Seek(item Interface) bool {
+	if item == nil {
+		return false
+	}
+	return c.CursorOf.Seek(item)
+}
+
+func (c cursor) Elem() Interface {
+	elem, _ := c.CursorOf.Elem()
+	return elem
+}
+
+// byteCursor wraps a CursorOf to give a ByteCursor
+type byteCursor struct {
+	CursorOf[Byte]
+}
+
+func (c byteCursor) Seek(item Byte) bool {
+	if item == nil {
+		return false
+	}
+	return c.CursorOf.Seek(item)
+}
+
+func (c byteCursor) Elem() Byte {
+	elem, _ := c.CursorOf.Elem()
+	return elem
 }
 
 // IterFunc is function we can give to our iterators to work with our stored types.
@@ -49,7 +210,8 @@ type TravOrder int
 // InOrder items are visted from smallest to largest, while RevOrder visits them from largest to smallest.
 // LevelOrder, PreOrder, PostOrder are dependent on the shape and layout of the underlying tree.
 // AnyOrder is where the algorithm is chosen for performance reasons.
-// RandOrder, items are visted in a uniformly random order.
+// RandOrder, items are visted in a uniformly random order. Each tree has a SetRand method to choose
+// the source of randomness, so a sequence may be repeated by seeding it.
 const (
 	InOrder TravOrder = iota
 	RevOrder
@@ -75,10 +237,74 @@ func (order TravOrder) String() string {
 		s = "level-order traversal"
 	case RevOrder:
 		s = "reverse-order traversal"
+	case AnyOrder:
+		s = "any-order traversal"
+	case RandOrder:
+		s = "random-order traversal"
 	}
 	return s
 }
 
+// shuffle returns a func which gives out elems in a uniformly random order, each call performing
+// one more step of a Fisher-Yates shuffle. When r is nil the global math/rand source is used.
+func shuffle[T any](elems []T, r *rand.Rand) func() (T, bool) {
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+	i := 0
+	return func() (out T, ok bool) {
+		if i == len(elems) {
+			return
+		}
+		j := i + intn(len(elems)-i)
+		elems[i], elems[j] = elems[j], elems[i]
+		out, ok = elems[i], true
+		i++
+		return
+	}
+}
+
+// Bounds describes which ends of a range [lo, hi) are included when traversing it.
+type Bounds int
+
+// ClosedOpen, the zero value, includes lo but not hi.
+const (
+	ClosedOpen Bounds = iota // [lo, hi)
+	Closed                   // [lo, hi]
+	Open                     // (lo, hi)
+	OpenClosed               // (lo, hi]
+)
+
+// human readable representation of Bounds values
+// used for debug and error reporting
+func (b Bounds) String() string {
+	var s string
+	switch b {
+	case ClosedOpen:
+		s = "[lo, hi)"
+	case Closed:
+		s = "[lo, hi]"
+	case Open:
+		s = "(lo, hi)"
+	case OpenClosed:
+		s = "(lo, hi]"
+	}
+	return s
+}
+
+// afterLo reports whether an element is within the lower bound, given bal which is the
+// elements relationship to lo.
+func (b Bounds) afterLo(bal Balance) bool {
+	return bal == GT || (bal == EQ && (b == ClosedOpen || b == Closed))
+}
+
+// beforeHi reports whether an element is within the upper bound, given bal which is the
+// elements relationship to hi.
+func (b Bounds) beforeHi(bal Balance) bool {
+	return bal == LT || (bal == EQ && (b == Closed || b == OpenClosed))
+}
+
 // Possible directions our path down the tree may take
 type Balance int
 
@@ -132,3 +358,49 @@ type Interface interface {
 type Byte interface {
 	ToBytes() []byte
 }
+
+// CompareFunc is the generic form of Interface.Compare, it answers "what is a's relationship to b?".
+type CompareFunc[T any] func(a, b T) Balance
+
+// KeyFunc is the generic form of Byte.ToBytes, it gives the key an item is to be stored under.
+// An empty key, nil included, is a key like any other.
+type KeyFunc[T any] func(item T) []byte
+
+// Ordered is a CompareFunc for any of the builtin ordered types. EX:
+//
+//     tree := NewRBTreeOf(Ordered[int])
+func Ordered[T cmp.Ordered](a, b T) Balance {
+	switch cmp.Compare(a, b) {
+	case 1:
+		return GT
+	case -1:
+		return LT
+	}
+	return EQ
+}
+
+// CmpFunc adapts a cmp.Compare style function, one which returns a negative, zero or positive int,
+// into a CompareFunc.
+func CmpFunc[T any](f func(a, b T) int) CompareFunc[T] {
+	return func(a, b T) Balance {
+		switch result := f(a, b); {
+		case result > 0:
+			return GT
+		case result < 0:
+			return LT
+		}
+		return EQ
+	}
+}
+
+// compareInterface is used by the generic trees when no CompareFunc was given,
+// which lets the zero value of a tree work with types implementing Interface.
+func compareInterface[T any](a, b T) Balance {
+	return any(a).(Interface).Compare(any(b).(Interface))
+}
+
+// keyByte is used by the generic byte trees when no KeyFunc was given,
+// which lets the zero value of a tree work with types implementing Byte.
+func keyByte[T any](item T) []byte {
+	return any(item).(Byte).ToBytes()
+}
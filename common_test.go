@@ -2,10 +2,8 @@ package gotree
 
 import (
 	"fmt"
-	"io/ioutil"
 	"math/rand"
 	"strconv"
-	"strings"
 	"testing"
 )
 
@@ -17,6 +15,8 @@ const (
 
 var trees = []Tree{&RBTree{}, &SplayTree{}}
 
+var genericTrees = []TreeOf[int]{NewRBTreeOf(Ordered[int]), NewSplayTreeOf(Ordered[int])}
+
 type exInt int
 
 func (this exInt) ToBytes() []byte {
@@ -366,6 +366,382 @@ func TestRemove(t *testing.T) {
 	}
 }
 
+func TestNeighbors(t *testing.T) {
+	for _, v := range trees {
+		tree := v
+		tree.Clear()
+
+		if check := tree.Floor(exInt(0)); check != nil {
+			t.Errorf("Not minding empty tree")
+		}
+		if check := tree.Successor(exInt(0)); check != nil {
+			t.Errorf("Not minding empty tree")
+		}
+		// only even numbers, so every odd query falls between two elements
+		r := rand.New(rand.NewSource(int64(5)))
+		for _, i := range r.Perm(iters) {
+			tree.Insert(exInt(i * 2))
+		}
+		if check := tree.Floor(nil); check != nil {
+			t.Errorf("Not minding nil key's")
+		}
+
+		max := (iters - 1) * 2
+		for i := -1; i <= max+1; i++ {
+			var floor, ceiling, pred, succ Interface
+			if i >= 0 {
+				floor = exInt(i - i%2)
+			}
+			if i <= max {
+				ceiling = exInt(i + i%2)
+			}
+			if i > 0 {
+				pred = exInt(i - 2 + (i % 2))
+			}
+			if i < max {
+				succ = exInt(i + 2 - (i % 2))
+			}
+			if i == -1 {
+				ceiling, succ = exInt(0), exInt(0)
+			}
+			if i == max+1 {
+				floor, pred = exInt(max), exInt(max)
+			}
+
+			if check := tree.Floor(exInt(i)); check != floor {
+				t.Errorf("%T Floor of %d Got: %v, Exp: %v", tree, i, check, floor)
+			}
+			if check := tree.Ceiling(exInt(i)); check != ceiling {
+				t.Errorf("%T Ceiling of %d Got: %v, Exp: %v", tree, i, check, ceiling)
+			}
+			if check := tree.Predecessor(exInt(i)); check != pred {
+				t.Errorf("%T Predecessor of %d Got: %v, Exp: %v", tree, i, check, pred)
+			}
+			if check := tree.Successor(exInt(i)); check != succ {
+				t.Errorf("%T Successor of %d Got: %v, Exp: %v", tree, i, check, succ)
+			}
+		}
+		// lookups must not disturb the elements
+		tree.Map(InOrder, inc(t))
+		if tree.Size() != iters {
+			t.Errorf("Size not correctly updateing")
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	for _, v := range trees {
+		tree := v
+		tree.Clear()
+
+		if check := tree.IterRangeInit(InOrder, exInt(0), exInt(10), Closed); check != nil {
+			t.Errorf("Not minding empty tree")
+		}
+		max := 1000
+		r := rand.New(rand.NewSource(int64(5)))
+		for _, i := range r.Perm(max) {
+			tree.Insert(exInt(i))
+		}
+
+		for _, bounds := range []Bounds{ClosedOpen, Closed, Open, OpenClosed} {
+			for k := 0; k < 50; k++ {
+				lo, hi := r.Intn(max+20)-10, r.Intn(max+20)-10
+				// expected elements in order
+				expected := []Interface{}
+				for i := lo; i <= hi; i++ {
+					if i < 0 || i >= max ||
+						(i == lo && (bounds == Open || bounds == OpenClosed)) ||
+						(i == hi && (bounds == Open || bounds == ClosedOpen)) {
+						continue
+					}
+					expected = append(expected, exInt(i))
+				}
+
+				i := 0
+				for n := tree.IterRangeInit(InOrder, exInt(lo), exInt(hi), bounds); n != nil; n = tree.Next() {
+					if i >= len(expected) || n != expected[i] {
+						t.Errorf("%T %s [%d, %d] Elems are in wrong order Got:%v", tree, bounds, lo, hi, n)
+						break
+					}
+					i++
+				}
+				if i != len(expected) {
+					t.Errorf("%T %s [%d, %d] Did not traverse all elements missing: %d", tree, bounds, lo, hi, len(expected)-i)
+				}
+
+				i = len(expected) - 1
+				for n := tree.IterRangeInit(RevOrder, exInt(lo), exInt(hi), bounds); n != nil; n = tree.Next() {
+					if i < 0 || n != expected[i] {
+						t.Errorf("%T %s [%d, %d] Elems are in wrong order Got:%v", tree, bounds, lo, hi, n)
+						break
+					}
+					i--
+				}
+				if i != -1 {
+					t.Errorf("%T %s [%d, %d] Did not traverse all elements missing: %d", tree, bounds, lo, hi, i+1)
+				}
+
+				i = 0
+				tree.MapRange(InOrder, exInt(lo), exInt(hi), bounds, func(n Interface) {
+					if i >= len(expected) || n != expected[i] {
+						t.Errorf("%T %s [%d, %d] Elems are in wrong order Got:%v", tree, bounds, lo, hi, n)
+					}
+					i++
+				})
+				if i != len(expected) {
+					t.Errorf("%T %s [%d, %d] Did not map all elements missing: %d", tree, bounds, lo, hi, len(expected)-i)
+				}
+
+				i = len(expected) - 1
+				tree.MapRange(RevOrder, exInt(lo), exInt(hi), bounds, func(n Interface) {
+					if i < 0 || n != expected[i] {
+						t.Errorf("%T %s [%d, %d] Elems are in wrong order Got:%v", tree, bounds, lo, hi, n)
+					}
+					i--
+				})
+				if i != -1 {
+					t.Errorf("%T %s [%d, %d] Did not map all elements missing: %d", tree, bounds, lo, hi, i+1)
+				}
+			}
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	for _, v := range trees {
+		tree := v
+		tree.Clear()
+
+		c := tree.Cursor()
+		if c.Next() || c.Prev() || c.Seek(exInt(0)) || c.Elem() != nil {
+			t.Errorf("Not minding empty tree")
+		}
+		max := 1000
+		r := rand.New(rand.NewSource(int64(5)))
+		// only even numbers, so odd seeks fall between two elements
+		for _, i := range r.Perm(max) {
+			tree.Insert(exInt(i * 2))
+		}
+
+		i := 0
+		for c := tree.Cursor(); c.Next(); i++ {
+			if c.Elem() != exInt(i*2) {
+				t.Errorf("%T Elems are in wrong order Got:%v, Exp: %d", tree, c.Elem(), i*2)
+			}
+		}
+		if i != max {
+			t.Errorf("%T Did not traverse all elements missing: %d", tree, max-i)
+		}
+		i = max - 1
+		for c := tree.Cursor(); c.Prev(); i-- {
+			if c.Elem() != exInt(i*2) {
+				t.Errorf("%T Elems are in wrong order Got:%v, Exp: %d", tree, c.Elem(), i*2)
+			}
+		}
+		if i != -1 {
+			t.Errorf("%T Did not traverse all elements missing: %d", tree, i+1)
+		}
+
+		// passing either end leaves the cursor unpositioned, ready to start again
+		c = tree.Cursor()
+		if c.Prev(); c.Next() || c.Elem() != nil {
+			t.Errorf("%T Should have left the tree", tree)
+		}
+		if c.Next(); c.Elem() != exInt(0) {
+			t.Errorf("%T Should have restarted Got: %v", tree, c.Elem())
+		}
+
+		for k := -1; k <= max*2; k++ {
+			exp := exInt(k + k%2)
+			if k == -1 {
+				exp = 0
+			}
+			if found := c.Seek(exInt(k)); found != (k < max*2-1) {
+				t.Errorf("%T Seek of %d wrong result %v", tree, k, found)
+				continue
+			} else if !found {
+				continue
+			}
+			if c.Elem() != exp {
+				t.Errorf("%T Seek of %d Got: %v, Exp: %v", tree, k, c.Elem(), exp)
+			}
+			if c.Next() != (exp < exInt(max*2-2)) || (c.Elem() != nil && c.Elem() != exp+2) {
+				t.Errorf("%T Next after seek of %d Got: %v", tree, k, c.Elem())
+			}
+			c.Seek(exInt(k))
+			if c.Prev() != (exp > 0) || (c.Elem() != nil && c.Elem() != exp-2) {
+				t.Errorf("%T Prev after seek of %d Got: %v", tree, k, c.Elem())
+			}
+		}
+		if c.Seek(nil) {
+			t.Errorf("Not minding nil key's")
+		}
+
+		// cursors are independent of each other and safe to share a read only tree
+		done := make(chan int)
+		for g := 0; g < 4; g++ {
+			go func() {
+				count := 0
+				a, b := tree.Cursor(), tree.Cursor()
+				for a.Next() && b.Prev() {
+					if a.Elem().Compare(b.Elem()) == GT {
+						break
+					}
+					count++
+				}
+				done <- count
+			}()
+		}
+		for g := 0; g < 4; g++ {
+			if count := <-done; count != max/2 {
+				t.Errorf("%T Cursors interfered with each other, count: %d", tree, count)
+			}
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	for _, v := range trees {
+		tree := v
+		tree.Clear()
+
+		for range tree.All(InOrder) {
+			t.Errorf("Not minding empty tree")
+		}
+		r := rand.New(rand.NewSource(int64(5)))
+		for i := 0; i < 1000; i++ {
+			tree.Insert(exInt(r.Intn(searchSpace)))
+		}
+
+		for _, order := range []TravOrder{InOrder, RevOrder} {
+			expected := []Interface{}
+			for n := tree.IterInit(order); n != nil; n = tree.Next() {
+				expected = append(expected, n)
+			}
+			i := 0
+			for n := range tree.All(order) {
+				if n != expected[i] {
+					t.Errorf("%T %s Elems are in wrong order Got:%v, Exp: %v", tree, order, n, expected[i])
+				}
+				i++
+			}
+			if i != len(expected) {
+				t.Errorf("%T %s Did not traverse all elements missing: %d", tree, order, len(expected)-i)
+			}
+
+			// breaking out early leaves nothing behind
+			i = 0
+			for n := range tree.All(order) {
+				if n != expected[i] {
+					t.Errorf("%T %s Elems are in wrong order Got:%v, Exp: %v", tree, order, n, expected[i])
+				}
+				if i == 10 {
+					break
+				}
+				i++
+			}
+		}
+
+		// shape dependent orders must still visit each element once
+		for _, order := range []TravOrder{PreOrder, PostOrder} {
+			seen := map[Interface]bool{}
+			for n := range tree.All(order) {
+				if seen[n] {
+					t.Errorf("%T %s Visited twice %v", tree, order, n)
+				}
+				seen[n] = true
+			}
+			if len(seen) != tree.Size() {
+				t.Errorf("%T %s Did not traverse all elements missing: %d", tree, order, tree.Size()-len(seen))
+			}
+		}
+	}
+}
+
+func TestOrders(t *testing.T) {
+	for _, v := range trees {
+		tree := v
+		tree.Clear()
+
+		for _, order := range []TravOrder{LevelOrder, AnyOrder, RandOrder} {
+			if n := tree.IterInit(order); n != nil {
+				t.Errorf("%T %s Not minding empty tree", tree, order)
+			}
+			tree.Map(order, func(n Interface) {
+				t.Errorf("%T %s Not minding empty tree", tree, order)
+			})
+		}
+
+		r := rand.New(rand.NewSource(int64(5)))
+		for i := 0; i < 1000; i++ {
+			tree.Insert(exInt(r.Intn(searchSpace)))
+		}
+
+		for _, order := range []TravOrder{LevelOrder, AnyOrder, RandOrder} {
+			// the same seed must give the same permutation each time
+			tree.SetRand(rand.New(rand.NewSource(int64(7))))
+			expected := []Interface{}
+			seen := map[Interface]bool{}
+			for n := tree.IterInit(order); n != nil; n = tree.Next() {
+				if seen[n] {
+					t.Errorf("%T %s Visited twice %v", tree, order, n)
+				}
+				seen[n] = true
+				expected = append(expected, n)
+			}
+			if len(seen) != tree.Size() {
+				t.Errorf("%T %s Did not traverse all elements missing: %d", tree, order, tree.Size()-len(seen))
+			}
+
+			tree.SetRand(rand.New(rand.NewSource(int64(7))))
+			i := 0
+			tree.Map(order, func(n Interface) {
+				if i < len(expected) && n != expected[i] {
+					t.Errorf("%T %s Map and Next disagree Got:%v, Exp: %v", tree, order, n, expected[i])
+				}
+				i++
+			})
+			if i != len(expected) {
+				t.Errorf("%T %s Map visited %d elements, Exp: %d", tree, order, i, len(expected))
+			}
+
+			tree.SetRand(rand.New(rand.NewSource(int64(7))))
+			i = 0
+			for n := range tree.All(order) {
+				if i < len(expected) && n != expected[i] {
+					t.Errorf("%T %s All and Next disagree Got:%v, Exp: %v", tree, order, n, expected[i])
+				}
+				i++
+			}
+			if i != len(expected) {
+				t.Errorf("%T %s All visited %d elements, Exp: %d", tree, order, i, len(expected))
+			}
+		}
+		tree.SetRand(nil)
+
+		// level order starts from the root, as pre order does
+		for root := range tree.All(PreOrder) {
+			if n := tree.IterInit(LevelOrder); n != root {
+				t.Errorf("%T LevelOrder did not start at the root Got:%v, Exp: %v", tree, n, root)
+			}
+			break
+		}
+
+		// a random permutation of sorted input is almost surely unsorted
+		sorted := true
+		prev := tree.IterInit(RandOrder)
+		for n := tree.Next(); n != nil; n = tree.Next() {
+			if n.Compare(prev) == LT {
+				sorted = false
+			}
+			prev = n
+		}
+		if sorted {
+			t.Errorf("%T RandOrder gave a sorted order", tree)
+		}
+	}
+}
+
 func TestRandomRemove(t *testing.T) {
 
 	for _, v := range trees {
@@ -495,6 +871,167 @@ func TestIterRevorder(t *testing.T) {
 
 }
 
+// every tree must support every order, through Map, IterInit and All alike
+func TestMap(t *testing.T) {
+	orders := []TravOrder{InOrder, RevOrder, PreOrder, PostOrder, LevelOrder, AnyOrder, RandOrder}
+	// the shape independent orders must agree across trees as well
+	shared := map[TravOrder][]Interface{}
+
+	for _, v := range trees {
+		tree := v
+		tree.Clear()
+		for _, order := range orders {
+			tree.Map(order, func(n Interface) {
+				t.Errorf("%T %s Not minding empty tree", tree, order)
+			})
+		}
+
+		r := rand.New(rand.NewSource(int64(5)))
+		for i := 0; i < 1000; i++ {
+			tree.Insert(exInt(r.Intn(searchSpace)))
+		}
+
+		for _, order := range orders {
+			tree.SetRand(rand.New(rand.NewSource(int64(7))))
+			expected := []Interface{}
+			tree.Map(order, func(n Interface) {
+				expected = append(expected, n)
+			})
+			if len(expected) != tree.Size() {
+				t.Errorf("%T %s Did not traverse all elements missing: %d", tree, order, tree.Size()-len(expected))
+			}
+
+			tree.SetRand(rand.New(rand.NewSource(int64(7))))
+			i := 0
+			for n := tree.IterInit(order); n != nil; i, n = i+1, tree.Next() {
+				if i < len(expected) && n != expected[i] {
+					t.Errorf("%T %s Map and Next disagree Got:%v, Exp: %v", tree, order, n, expected[i])
+				}
+			}
+			if i != len(expected) {
+				t.Errorf("%T %s Next visited %d elements, Exp: %d", tree, order, i, len(expected))
+			}
+
+			tree.SetRand(rand.New(rand.NewSource(int64(7))))
+			i = 0
+			for n := range tree.All(order) {
+				if i < len(expected) && n != expected[i] {
+					t.Errorf("%T %s Map and All disagree Got:%v, Exp: %v", tree, order, n, expected[i])
+				}
+				i++
+			}
+			if i != len(expected) {
+				t.Errorf("%T %s All visited %d elements, Exp: %d", tree, order, i, len(expected))
+			}
+
+			switch order {
+			case InOrder, RevOrder, RandOrder:
+				if other, ok := shared[order]; !ok {
+					shared[order] = expected
+				} else {
+					for i := range other {
+						if i < len(expected) && other[i] != expected[i] {
+							t.Errorf("%T %s Trees disagree Got:%v, Exp: %v", tree, order, expected[i], other[i])
+							break
+						}
+					}
+				}
+			}
+		}
+		tree.SetRand(nil)
+
+		// the root is visited first in pre order, and last in post order
+		pre, post := []Interface{}, []Interface{}
+		tree.Map(PreOrder, func(n Interface) { pre = append(pre, n) })
+		tree.Map(PostOrder, func(n Interface) { post = append(post, n) })
+		if pre[0] != post[len(post)-1] {
+			t.Errorf("%T PreOrder and PostOrder disagree on the root Got:%v, Exp: %v", tree, post[len(post)-1], pre[0])
+		}
+	}
+}
+
+func TestGeneric(t *testing.T) {
+	for _, tree := range genericTrees {
+		tree.Clear()
+
+		if _, ok := tree.Min(); ok {
+			t.Errorf("Not minding empty tree")
+		}
+		r := rand.New(rand.NewSource(int64(5)))
+		perm := r.Perm(iters)
+		for _, v := range perm {
+			if _, ok := tree.Insert(v); ok {
+				t.Errorf("Nothing should have been replaced")
+			}
+		}
+		// zero is a valid element, not a missing one
+		if old, ok := tree.Insert(0); !ok || old != 0 {
+			t.Errorf("Zero value not replaced Got: %d", old)
+		}
+		if tree.Size() != iters {
+			t.Errorf("Size not correctly updateing")
+		}
+		if min, _ := tree.Min(); min != 0 {
+			t.Errorf("Min not updateing Got: %d", min)
+		}
+		if max, _ := tree.Max(); max != iters-1 {
+			t.Errorf("Max not updateing Got: %d", max)
+		}
+
+		prior := -1
+		tree.Map(InOrder, func(n int) {
+			if n != prior+1 {
+				t.Errorf("Elems are in wrong order Got:%d, Exp: %d", n, prior+1)
+			}
+			prior = n
+		})
+		i := iters - 1
+		for n, ok := tree.IterInit(RevOrder); ok; n, ok = tree.Next() {
+			if n != i {
+				t.Errorf("Elems are in wrong order Got:%d, Exp: %d", n, i)
+			}
+			i--
+		}
+
+		for _, v := range perm {
+			if found, ok := tree.Search(v); !ok || found != v {
+				t.Errorf("Values don't match Exp: %d, Got: %d", v, found)
+			}
+			if old, ok := tree.Remove(v); !ok || old != v {
+				t.Errorf("Not getting removed item back Exp: %d, Got: %d", v, old)
+			}
+			if _, ok := tree.Search(v); ok {
+				t.Errorf("Didn't really remove")
+			}
+		}
+		if _, ok := tree.Remove(0); ok || tree.Size() != 0 {
+			t.Errorf("Not respecting empty tree.")
+		}
+	}
+}
+
+func TestGenericZeroValue(t *testing.T) {
+	// without a CompareFunc the elements own Compare method is used
+	tree := &RBTreeOf[exInt]{}
+	for i := 0; i < 10; i++ {
+		tree.Insert(exInt(i))
+	}
+	if found, ok := tree.Search(exInt(5)); !ok || found != 5 {
+		t.Errorf("Values don't match Exp: %d, Got: %d", 5, found)
+	}
+
+	lens := NewSplayTreeOf(CmpFunc(func(a, b string) int { return len(a) - len(b) }))
+	for _, v := range []string{"ccc", "a", "bb"} {
+		lens.Insert(v)
+	}
+	if max, _ := lens.Max(); max != "ccc" {
+		t.Errorf("Max not updateing Got: %s", max)
+	}
+	if found, ok := lens.Search("zz"); !ok || found != "bb" {
+		t.Errorf("Values don't match Exp: %s, Got: %s", "bb", found)
+	}
+}
+
 func benchSearch(tree Tree) func(b *testing.B) {
 	tree.Clear()
 	return func(b *testing.B) {
@@ -635,11 +1172,7 @@ func benchText(tree Tree) func(b *testing.B) {
 	tree.Clear()
 	return func(b *testing.B) {
 		b.StopTimer()
-		content, err := ioutil.ReadFile("misc/testText.txt")
-		if err != nil {
-			panic("Couldn't read in file to benchmark on")
-		}
-		data := strings.Fields(string(content))
+		data := loadText(b)
 		fmt.Println(len(data))
 		b.StartTimer()
 		for i := 0; i < b.N; i++ {
@@ -2,6 +2,8 @@ package gotree
 
 import (
 	"fmt"
+	"iter"
+	"math/rand"
 	"runtime"
 )
 
@@ -25,33 +27,58 @@ func (c color) String() string {
 	return s
 }
 
-// A RBNode is the type manipulated within the tree. It holds the inserted elements.
+// A RBNodeOf is the type manipulated within the tree. It holds the inserted elements.
 // It is exposed whenever the tree traversal functions are used.
-type RBNode struct {
-	Elem        Interface
-	left, right *RBNode
+type RBNodeOf[T any] struct {
+	Elem        T
+	left, right *RBNodeOf[T]
 	color       color
+	size        int // Number of nodes in the subtree rooted here
 }
 
-// A RBTree is our main type our redblack tree methods are defined on.
-type RBTree struct {
+// A RBNode is the node type of the Interface based RBTree.
+type RBNode = RBNodeOf[Interface]
+
+// A RBTreeOf is the type parameterized redblack tree, elements are ordered by its CompareFunc.
+// The zero value uses the elements own Compare method, and so is only usable for types implementing Interface.
+type RBTreeOf[T any] struct {
 	height      int // height from root to leaf
 	size        int // Number of inserted elements
-	first, last *RBNode
-	iterNext    func() Interface // initially nil
-	root        *RBNode
+	first, last *RBNodeOf[T]
+	iterNext    func() (T, bool) // initially nil
+	root        *RBNodeOf[T]
+	cmp         CompareFunc[T]
+	rand        *rand.Rand // source for RandOrder, nil for the global source
+}
+
+// NewRBTreeOf returns an empty tree which orders its elements using cmp.
+func NewRBTreeOf[T any](cmp CompareFunc[T]) *RBTreeOf[T] {
+	return &RBTreeOf[T]{cmp: cmp}
+}
+
+// A RBTree is our main type our redblack tree methods are defined on.
+// It is a thin wrapper around RBTreeOf which uses nil to signal a missing element.
+type RBTree struct {
+	RBTreeOf[Interface]
+}
+
+func (t *RBTreeOf[T]) compare(a, b T) Balance {
+	if t.cmp == nil {
+		return compareInterface(a, b)
+	}
+	return t.cmp(a, b)
 }
 
 // Height returns the max depth of any branch of the tree
-func (t *RBTree) Height() int {
+func (t *RBTreeOf[T]) Height() int {
 	return t.height
 }
 
 // Size returns the number of elements currently inserted in the tree.
-func (t *RBTree) Size() int {
+func (t *RBTreeOf[T]) Size() int {
 	return t.size
 }
-func (t *RBTree) Clear() {
+func (t *RBTreeOf[T]) Clear() {
 	t.root = nil
 	t.last = nil
 	t.first = nil
@@ -61,22 +88,37 @@ func (t *RBTree) Clear() {
 	runtime.GC()
 }
 
+// SetRand chooses the source of randomness used by RandOrder traversals, nil being the global
+// math/rand source. A *rand.Rand is not safe for concurrent use, so neither are RandOrder traversals sharing one.
+func (t *RBTreeOf[T]) SetRand(r *rand.Rand) {
+	t.rand = r
+}
+
+// all the elements, for the orders which must see everything before starting
+func (t *RBTreeOf[T]) elems() []T {
+	elems := make([]T, 0, t.size)
+	t.Map(InOrder, func(elem T) {
+		elems = append(elems, elem)
+	})
+	return elems
+}
+
 // Min returns the smallest inserted element if possible. If the smallest value is not
-// found(empty tree), then Min returns a nil.
-func (t *RBTree) Min() Interface {
+// found(empty tree), then ok is false.
+func (t *RBTreeOf[T]) Min() (min T, ok bool) {
 	if t.first != nil {
-		return t.first.Elem
+		return t.first.Elem, true
 	}
-	return nil
+	return
 }
 
 // Max returns the largest inserted element if possible. If the largest value is not
-// found(empty tree), then Max returns a nil.
-func (t *RBTree) Max() Interface {
+// found(empty tree), then ok is false.
+func (t *RBTreeOf[T]) Max() (max T, ok bool) {
 	if t.last != nil {
-		return t.last.Elem
+		return t.last.Elem, true
 	}
-	return nil
+	return
 }
 
 // Next is called when individual elements are wanted to be traversed over.
@@ -84,30 +126,29 @@ func (t *RBTree) Max() Interface {
 // data to allow for traversal of the tree. Example:
 //
 //    sum := 0
-//    for i, n := 0, tree.IterInit(InOrder); n != nil; i, n = i+1, tree.Next() {
-//        elem := n.(exInt)  // (exInt is simple int type)
-//        sum += int(elem) + i
+//    for n, ok := tree.IterInit(InOrder); ok; n, ok = tree.Next() {
+//        sum += n
 //    }
 // Note: If one was to break out of the loop prior to a complete traversal,
 // and start another loop without calling IterInit, then the previously uncompleted iterator is continued again.
-func (t *RBTree) Next() Interface {
+func (t *RBTreeOf[T]) Next() (next T, ok bool) {
 
 	if t.iterNext == nil {
-		return nil
+		return
 	}
 	return t.iterNext() // func set by call to IterInit(TravOrder)
 
 }
 
 // IterInit is the initializer which setups the tree for iterating over it's elements in
-// a specific order. It setups the internal data, and then returns the first RBNode to be looked at. See Next for an example.
-func (t *RBTree) IterInit(order TravOrder) Interface {
+// a specific order. It setups the internal data, and then returns the first element to be looked at. See Next for an example.
+func (t *RBTreeOf[T]) IterInit(order TravOrder) (T, bool) {
 
 	current := t.root
-	stack := []*RBNode{}
+	stack := []*RBNodeOf[T]{}
 	switch order {
 	case InOrder:
-		t.iterNext = func() (out Interface) {
+		t.iterNext = func() (out T, ok bool) {
 			for len(stack) > 0 || current != nil {
 				if current != nil {
 					stack = append(stack, current)
@@ -116,20 +157,20 @@ func (t *RBTree) IterInit(order TravOrder) Interface {
 					// pop
 					stackIndex := len(stack) - 1
 					current = stack[stackIndex]
-					out = current.Elem
+					out, ok = current.Elem, true
 					stack = stack[0:stackIndex]
 					current = current.right
 					break
 				}
 			}
 			// last node, reset
-			if out == nil {
+			if !ok {
 				t.iterNext = nil
 			}
-			return out
+			return
 		}
 	case RevOrder:
-		t.iterNext = func() (out Interface) {
+		t.iterNext = func() (out T, ok bool) {
 			for len(stack) > 0 || current != nil {
 				if current != nil {
 					stack = append(stack, current)
@@ -138,24 +179,24 @@ func (t *RBTree) IterInit(order TravOrder) Interface {
 					// pop
 					stackIndex := len(stack) - 1
 					current = stack[stackIndex]
-					out = current.Elem
+					out, ok = current.Elem, true
 					stack = stack[0:stackIndex]
 					current = current.left
 					break
 				}
 			}
 			// last node, reset
-			if out == nil {
+			if !ok {
 				t.iterNext = nil
 			}
-			return out
+			return
 		}
 
-	case PreOrder:
-		t.iterNext = func() (out Interface) {
+	case PreOrder, AnyOrder:
+		t.iterNext = func() (out T, ok bool) {
 			for len(stack) > 0 || current != nil {
 				if current != nil {
-					out = current.Elem
+					out, ok = current.Elem, true
 					stack = append(stack, current.right)
 					current = current.left
 					break
@@ -168,16 +209,18 @@ func (t *RBTree) IterInit(order TravOrder) Interface {
 			}
 
 			// last node, reset
-			if out == nil {
+			if !ok {
 				t.iterNext = nil
 			}
-			return out
+			return
 		}
 	case PostOrder:
-		stack = append(stack, current)
-		var prevRBNode *RBNode
+		if current != nil {
+			stack = append(stack, current)
+		}
+		var prevRBNode *RBNodeOf[T]
 
-		t.iterNext = func() (out Interface) {
+		t.iterNext = func() (out T, ok bool) {
 			for len(stack) > 0 {
 				// peek
 				stackIndex := len(stack) - 1
@@ -195,7 +238,7 @@ func (t *RBTree) IterInit(order TravOrder) Interface {
 						stack = append(stack, current.right)
 					}
 				} else {
-					out = current.Elem
+					out, ok = current.Elem, true
 					// pop, but no assignment
 					stackIndex := len(stack) - 1
 					stack = stack[0:stackIndex]
@@ -206,12 +249,46 @@ func (t *RBTree) IterInit(order TravOrder) Interface {
 			}
 
 			// last node, reset
-			if out == nil {
+			if !ok {
 				t.iterNext = nil
 			}
-			return out
+			return
 
 		}
+	case LevelOrder:
+		queue := []*RBNodeOf[T]{}
+		if current != nil {
+			queue = append(queue, current)
+		}
+		t.iterNext = func() (out T, ok bool) {
+			if len(queue) > 0 {
+				// dequeue
+				current = queue[0]
+				queue = queue[1:]
+				out, ok = current.Elem, true
+				if current.left != nil {
+					queue = append(queue, current.left)
+				}
+				if current.right != nil {
+					queue = append(queue, current.right)
+				}
+			}
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+		}
+	case RandOrder:
+		next := shuffle(t.elems(), t.rand)
+		t.iterNext = func() (out T, ok bool) {
+			out, ok = next()
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+		}
 	default:
 		s := fmt.Sprintf("rbTree has not implemented %s for iteration.", order)
 		panic(s)
@@ -222,18 +299,15 @@ func (t *RBTree) IterInit(order TravOrder) Interface {
 }
 
 // Map is a more performance orientated way to iterate over the elements of the tree.
-// Given a TravOrder and a function which conforms to the IterFunc type:
-//
-//      type IterFunc func(Interface)
-//
+// Given a TravOrder and a function to call with each element,
 // Map calls the function for each RBNode  in the specified order.
-func (t *RBTree) Map(order TravOrder, f IterFunc) {
+func (t *RBTreeOf[T]) Map(order TravOrder, f func(T)) {
 
 	n := t.root
 	switch order {
 	case InOrder:
-		var inorder func(node *RBNode)
-		inorder = func(node *RBNode) {
+		var inorder func(node *RBNodeOf[T])
+		inorder = func(node *RBNodeOf[T]) {
 			if node == nil {
 				return
 			}
@@ -242,9 +316,20 @@ func (t *RBTree) Map(order TravOrder, f IterFunc) {
 			inorder(node.right)
 		}
 		inorder(n)
-	case PreOrder:
-		var preorder func(node *RBNode)
-		preorder = func(node *RBNode) {
+	case RevOrder:
+		var revorder func(node *RBNodeOf[T])
+		revorder = func(node *RBNodeOf[T]) {
+			if node == nil {
+				return
+			}
+			revorder(node.right)
+			f(node.Elem)
+			revorder(node.left)
+		}
+		revorder(n)
+	case PreOrder, AnyOrder:
+		var preorder func(node *RBNodeOf[T])
+		preorder = func(node *RBNodeOf[T]) {
 			if node == nil {
 				return
 			}
@@ -254,8 +339,8 @@ func (t *RBTree) Map(order TravOrder, f IterFunc) {
 		}
 		preorder(n)
 	case PostOrder:
-		var postorder func(node *RBNode)
-		postorder = func(node *RBNode) {
+		var postorder func(node *RBNodeOf[T])
+		postorder = func(node *RBNodeOf[T]) {
 			if node == nil {
 				return
 			}
@@ -264,6 +349,18 @@ func (t *RBTree) Map(order TravOrder, f IterFunc) {
 			f(node.Elem)
 		}
 		postorder(n)
+	case LevelOrder:
+		for queue := []*RBNodeOf[T]{n}; len(queue) > 0; queue = queue[1:] {
+			if node := queue[0]; node != nil {
+				f(node.Elem)
+				queue = append(queue, node.left, node.right)
+			}
+		}
+	case RandOrder:
+		next := shuffle(t.elems(), t.rand)
+		for elem, ok := next(); ok; elem, ok = next() {
+			f(elem)
+		}
 	default:
 		s := fmt.Sprintf("rbTree has not implemented %s.", order)
 		panic(s)
@@ -271,17 +368,287 @@ func (t *RBTree) Map(order TravOrder, f IterFunc) {
 
 }
 
-// Search returns the matching item if found, otherwise nil is returned.
-func (t *RBTree) Search(item Interface) (found Interface) {
-	if item == nil {
+// All returns an iterator over the elements of the tree in the given order, for use with range:
+//
+//    for elem := range tree.All(InOrder) {
+//        fmt.Println(elem)
+//    }
+// Breaking out of the loop early is fine, as no state is kept within the tree.
+func (t *RBTreeOf[T]) All(order TravOrder) iter.Seq[T] {
+	var walk func(node *RBNodeOf[T], yield func(T) bool) bool
+	switch order {
+	case InOrder:
+		walk = func(node *RBNodeOf[T], yield func(T) bool) bool {
+			return node == nil ||
+				walk(node.left, yield) && yield(node.Elem) && walk(node.right, yield)
+		}
+	case RevOrder:
+		walk = func(node *RBNodeOf[T], yield func(T) bool) bool {
+			return node == nil ||
+				walk(node.right, yield) && yield(node.Elem) && walk(node.left, yield)
+		}
+	case PreOrder, AnyOrder:
+		walk = func(node *RBNodeOf[T], yield func(T) bool) bool {
+			return node == nil ||
+				yield(node.Elem) && walk(node.left, yield) && walk(node.right, yield)
+		}
+	case PostOrder:
+		walk = func(node *RBNodeOf[T], yield func(T) bool) bool {
+			return node == nil ||
+				walk(node.left, yield) && walk(node.right, yield) && yield(node.Elem)
+		}
+	case LevelOrder:
+		return func(yield func(T) bool) {
+			for queue := []*RBNodeOf[T]{t.root}; len(queue) > 0; queue = queue[1:] {
+				if node := queue[0]; node != nil {
+					if !yield(node.Elem) {
+						return
+					}
+					queue = append(queue, node.left, node.right)
+				}
+			}
+		}
+	case RandOrder:
+		return func(yield func(T) bool) {
+			next := shuffle(t.elems(), t.rand)
+			for elem, ok := next(); ok && yield(elem); elem, ok = next() {
+			}
+		}
+	default:
+		s := fmt.Sprintf("rbTree has not implemented %s for iteration.", order)
+		panic(s)
+	}
+	return func(yield func(T) bool) {
+		walk(t.root, yield)
+	}
+}
+
+// IterRangeInit is like IterInit, but only the elements between lo and hi are visited. Bounds
+// chooses whether lo and hi themselves are included. Only InOrder and RevOrder are possible, with
+// RevOrder starting from hi. The traversal descends directly to its starting bound, so the cost is
+// that of a search plus the number of elements visited. See Next for an example.
+func (t *RBTreeOf[T]) IterRangeInit(order TravOrder, lo, hi T, bounds Bounds) (T, bool) {
+
+	stack := []*RBNodeOf[T]{}
+	switch order {
+	case InOrder:
+		// path down to the smallest element within the lower bound
+		for current := t.root; current != nil; {
+			if bounds.afterLo(t.compare(current.Elem, lo)) {
+				stack = append(stack, current)
+				current = current.left
+			} else {
+				current = current.right
+			}
+		}
+		t.iterNext = func() (out T, ok bool) {
+			if len(stack) > 0 {
+				// pop
+				stackIndex := len(stack) - 1
+				current := stack[stackIndex]
+				stack = stack[0:stackIndex]
+				if bounds.beforeHi(t.compare(current.Elem, hi)) {
+					out, ok = current.Elem, true
+					for current = current.right; current != nil; current = current.left {
+						stack = append(stack, current)
+					}
+				}
+			}
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+		}
+	case RevOrder:
+		// path down to the largest element within the upper bound
+		for current := t.root; current != nil; {
+			if bounds.beforeHi(t.compare(current.Elem, hi)) {
+				stack = append(stack, current)
+				current = current.right
+			} else {
+				current = current.left
+			}
+		}
+		t.iterNext = func() (out T, ok bool) {
+			if len(stack) > 0 {
+				// pop
+				stackIndex := len(stack) - 1
+				current := stack[stackIndex]
+				stack = stack[0:stackIndex]
+				if bounds.afterLo(t.compare(current.Elem, lo)) {
+					out, ok = current.Elem, true
+					for current = current.left; current != nil; current = current.right {
+						stack = append(stack, current)
+					}
+				}
+			}
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+		}
+	default:
+		s := fmt.Sprintf("rbTree has not implemented %s for range iteration.", order)
+		panic(s)
+	}
+	// return our first node
+	return t.iterNext()
+}
+
+// MapRange is like Map, but only the elements between lo and hi are visited. Bounds
+// chooses whether lo and hi themselves are included. Only InOrder and RevOrder are possible.
+// Subtrees entirely outside of the range are never entered.
+func (t *RBTreeOf[T]) MapRange(order TravOrder, lo, hi T, bounds Bounds, f func(T)) {
+
+	n := t.root
+	switch order {
+	case InOrder:
+		var inorder func(node *RBNodeOf[T])
+		inorder = func(node *RBNodeOf[T]) {
+			if node == nil {
+				return
+			}
+			afterLo := bounds.afterLo(t.compare(node.Elem, lo))
+			beforeHi := bounds.beforeHi(t.compare(node.Elem, hi))
+			if afterLo {
+				inorder(node.left)
+			}
+			if afterLo && beforeHi {
+				f(node.Elem)
+			}
+			if beforeHi {
+				inorder(node.right)
+			}
+		}
+		inorder(n)
+	case RevOrder:
+		var revorder func(node *RBNodeOf[T])
+		revorder = func(node *RBNodeOf[T]) {
+			if node == nil {
+				return
+			}
+			afterLo := bounds.afterLo(t.compare(node.Elem, lo))
+			beforeHi := bounds.beforeHi(t.compare(node.Elem, hi))
+			if beforeHi {
+				revorder(node.right)
+			}
+			if afterLo && beforeHi {
+				f(node.Elem)
+			}
+			if afterLo {
+				revorder(node.left)
+			}
+		}
+		revorder(n)
+	default:
+		s := fmt.Sprintf("rbTree has not implemented %s for range mapping.", order)
+		panic(s)
+	}
+}
+
+// rbCursor is the CursorOf a RBTreeOf, it holds the path from the root down to its current node.
+type rbCursor[T any] struct {
+	tree *RBTreeOf[T]
+	path []*RBNodeOf[T] // empty when not positioned
+}
+
+// Cursor returns a new unpositioned cursor over the tree. See CursorOf for an example.
+func (t *RBTreeOf[T]) Cursor() CursorOf[T] {
+	return &rbCursor[T]{tree: t}
+}
+
+func (c *rbCursor[T]) Elem() (elem T, ok bool) {
+	if len(c.path) == 0 {
 		return
 	}
+	return c.path[len(c.path)-1].Elem, true
+}
+
+func (c *rbCursor[T]) Next() bool {
+	if len(c.path) == 0 {
+		// start from the smallest
+		for x := c.tree.root; x != nil; x = x.left {
+			c.path = append(c.path, x)
+		}
+		return len(c.path) > 0
+	}
+	if x := c.path[len(c.path)-1].right; x != nil {
+		// smallest of the right subtree
+		for ; x != nil; x = x.left {
+			c.path = append(c.path, x)
+		}
+		return true
+	}
+	// go up until we leave a left subtree
+	for {
+		child := c.path[len(c.path)-1]
+		c.path = c.path[:len(c.path)-1]
+		if len(c.path) == 0 {
+			return false
+		}
+		if c.path[len(c.path)-1].left == child {
+			return true
+		}
+	}
+}
+
+func (c *rbCursor[T]) Prev() bool {
+	if len(c.path) == 0 {
+		// start from the largest
+		for x := c.tree.root; x != nil; x = x.right {
+			c.path = append(c.path, x)
+		}
+		return len(c.path) > 0
+	}
+	if x := c.path[len(c.path)-1].left; x != nil {
+		// largest of the left subtree
+		for ; x != nil; x = x.right {
+			c.path = append(c.path, x)
+		}
+		return true
+	}
+	// go up until we leave a right subtree
+	for {
+		child := c.path[len(c.path)-1]
+		c.path = c.path[:len(c.path)-1]
+		if len(c.path) == 0 {
+			return false
+		}
+		if c.path[len(c.path)-1].right == child {
+			return true
+		}
+	}
+}
+
+func (c *rbCursor[T]) Seek(item T) bool {
+	c.path = c.path[:0]
+	// depth of the smallest node found so far which is greater than item
+	ceiling := 0
+	for x := c.tree.root; x != nil; {
+		c.path = append(c.path, x)
+		switch c.tree.compare(x.Elem, item) {
+		case EQ:
+			return true
+		case GT:
+			ceiling = len(c.path)
+			x = x.left
+		case LT:
+			x = x.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return ceiling > 0
+}
+
+// Search returns the matching item if found, otherwise ok is false.
+func (t *RBTreeOf[T]) Search(item T) (found T, ok bool) {
 	x := t.root
 	for x != nil {
-		switch x.Elem.Compare(item) {
+		switch t.compare(x.Elem, item) {
 		case EQ:
-			found = x.Elem
-			return
+			return x.Elem, true
 		case GT:
 			x = x.left
 		case LT:
@@ -291,19 +658,16 @@ func (t *RBTree) Search(item Interface) (found Interface) {
 	return
 }
 
-// Insert will either insert a new entry into the tree, and return nil. Or if there was a previous entry already inserted, then in addition to inserting the new item, the previously inserted item will be returned.
-func (t *RBTree) Insert(item Interface) (old Interface) {
-	if item == nil {
-		return
-	}
+// Insert will either insert a new entry into the tree, and return with ok being false. Or if there was a previous entry already inserted, then in addition to inserting the new item, the previously inserted item will be returned.
+func (t *RBTreeOf[T]) Insert(item T) (old T, ok bool) {
 
 	if t.root == nil {
 		t.size++
-		t.root = &RBNode{Elem: item, left: nil, right: nil}
+		t.root = &RBNodeOf[T]{Elem: item, left: nil, right: nil, size: 1}
 		t.first = t.root
 		t.last = t.root
 	} else {
-		t.root, old = t.insert(t.root, item)
+		t.root, old, ok = t.insert(t.root, item)
 	}
 
 	if t.root.color == red {
@@ -313,18 +677,18 @@ func (t *RBTree) Insert(item Interface) (old Interface) {
 	return
 }
 
-func (t *RBTree) insert(h *RBNode, item Interface) (root *RBNode, old Interface) {
+func (t *RBTreeOf[T]) insert(h *RBNodeOf[T], item T) (root *RBNodeOf[T], old T, ok bool) {
 	if h == nil {
 		t.size++
 		// base case, insert do stuff on new node
-		n := &RBNode{Elem: item, left: nil, right: nil}
+		n := &RBNodeOf[T]{Elem: item, left: nil, right: nil, size: 1}
 		// set Min
-		switch t.first.Elem.Compare(item) {
+		switch t.compare(t.first.Elem, item) {
 		case GT:
 			t.first = n
 		}
 		// set Max
-		switch t.last.Elem.Compare(item) {
+		switch t.compare(t.last.Elem, item) {
 		case LT:
 			t.last = n
 		}
@@ -332,15 +696,16 @@ func (t *RBTree) insert(h *RBNode, item Interface) (root *RBNode, old Interface)
 		return
 	}
 
-	switch h.Elem.Compare(item) {
+	switch t.compare(h.Elem, item) {
 	case GT:
-		h.left, old = t.insert(h.left, item)
+		h.left, old, ok = t.insert(h.left, item)
 	case LT:
-		h.right, old = t.insert(h.right, item)
+		h.right, old, ok = t.insert(h.right, item)
 	case EQ:
-		old = h.Elem
+		old, ok = h.Elem, true
 		h.Elem = item
 	}
+	h.resize()
 
 	if h.right.isred() && !(h.left.isred()) {
 		h = h.rotateLeft()
@@ -356,32 +721,30 @@ func (t *RBTree) insert(h *RBNode, item Interface) (root *RBNode, old Interface)
 	return
 }
 
-// Remove looks for a matching entry, and if found, the item is removed from the tree and old is populated with the removed item. If the item is not matched in the tree, nil is returned.
-func (t *RBTree) Remove(item Interface) (old Interface) {
-	if item == nil || t.root == nil {
+// Remove looks for a matching entry, and if found, the item is removed from the tree and old is populated with the removed item. If the item is not matched in the tree, ok is false.
+func (t *RBTreeOf[T]) Remove(item T) (old T, ok bool) {
+	if t.root == nil {
 		return
 	}
-	t.root, old = t.remove(t.root, item)
-	if old != nil {
+	t.root, old, ok = t.remove(t.root, item)
+	if ok {
 		if t.root == nil {
 			t.first = nil
 			t.last = nil
 		} else {
 			// set Min
-			switch t.first.Elem.Compare(old) {
+			switch t.compare(t.first.Elem, old) {
 			case EQ:
 				t.first = t.root.min()
 			}
 			// set Max
-			switch t.last.Elem.Compare(old) {
+			switch t.compare(t.last.Elem, old) {
 			case EQ:
 				t.last = t.root.max()
 
 			}
 
 		}
-	} else {
-
 	}
 	if t.root != nil && t.root.color == red {
 		t.root.color = black // maintain rb invariants
@@ -393,16 +756,16 @@ func (t *RBTree) Remove(item Interface) (old Interface) {
 
 }
 
-func (t *RBTree) remove(h *RBNode, item Interface) (root *RBNode, old Interface) {
+func (t *RBTreeOf[T]) remove(h *RBNodeOf[T], item T) (root *RBNodeOf[T], old T, ok bool) {
 
-	switch h.Elem.Compare(item) {
+	switch t.compare(h.Elem, item) {
 	case LT, EQ:
 		if h.left.isred() {
 			h = h.rotateRight()
 		}
-		if result := h.Elem.Compare(item); result == EQ && h.right == nil {
+		if result := t.compare(h.Elem, item); result == EQ && h.right == nil {
 			t.size--
-			old = h.Elem
+			old, ok = h.Elem, true
 			h = nil
 			root = nil
 			return
@@ -411,15 +774,15 @@ func (t *RBTree) remove(h *RBNode, item Interface) (root *RBNode, old Interface)
 			if !h.right.isred() && !(h.right.left.isred()) {
 				h = h.moveredRight()
 			}
-			if result := h.Elem.Compare(item); result == EQ {
-				old = h.Elem
+			if result := t.compare(h.Elem, item); result == EQ {
+				old, ok = h.Elem, true
 				t.size--
 
 				x := h.right.min()
 				h.Elem = x.Elem
 				h.right = h.right.removeMin()
 			} else {
-				h.right, old = t.remove(h.right, item)
+				h.right, old, ok = t.remove(h.right, item)
 			}
 		}
 	case GT:
@@ -427,7 +790,7 @@ func (t *RBTree) remove(h *RBNode, item Interface) (root *RBNode, old Interface)
 			if !h.left.isred() && !(h.left.left.isred()) {
 				h = h.moveredLeft()
 			}
-			h.left, old = t.remove(h.left, item)
+			h.left, old, ok = t.remove(h.left, item)
 		}
 
 	}
@@ -435,31 +798,323 @@ func (t *RBTree) remove(h *RBNode, item Interface) (root *RBNode, old Interface)
 	return
 }
 
+// Floor returns the largest element less than or equal to item, if there is none ok is false.
+func (t *RBTreeOf[T]) Floor(item T) (found T, ok bool) {
+	return t.below(item, true)
+}
+
+// Ceiling returns the smallest element greater than or equal to item, if there is none ok is false.
+func (t *RBTreeOf[T]) Ceiling(item T) (found T, ok bool) {
+	return t.above(item, true)
+}
+
+// Predecessor returns the largest element strictly less than item, if there is none ok is false.
+func (t *RBTreeOf[T]) Predecessor(item T) (found T, ok bool) {
+	return t.below(item, false)
+}
+
+// Successor returns the smallest element strictly greater than item, if there is none ok is false.
+func (t *RBTreeOf[T]) Successor(item T) (found T, ok bool) {
+	return t.above(item, false)
+}
+
+// below finds the closest element less than item, and equal to it as well when inclusive.
+func (t *RBTreeOf[T]) below(item T, inclusive bool) (found T, ok bool) {
+	x := t.root
+	for x != nil {
+		switch t.compare(x.Elem, item) {
+		case EQ:
+			if inclusive {
+				return x.Elem, true
+			}
+			x = x.left
+		case GT:
+			x = x.left
+		case LT:
+			// a candidate, but something closer may be to the right
+			found, ok = x.Elem, true
+			x = x.right
+		}
+	}
+	return
+}
+
+// above finds the closest element greater than item, and equal to it as well when inclusive.
+func (t *RBTreeOf[T]) above(item T, inclusive bool) (found T, ok bool) {
+	x := t.root
+	for x != nil {
+		switch t.compare(x.Elem, item) {
+		case EQ:
+			if inclusive {
+				return x.Elem, true
+			}
+			x = x.right
+		case LT:
+			x = x.right
+		case GT:
+			// a candidate, but something closer may be to the left
+			found, ok = x.Elem, true
+			x = x.left
+		}
+	}
+	return
+}
+
+// Rank returns the number of elements in the tree which are less than item.
+// When item is in the tree this is its index within an InOrder traversal.
+// Runs in O(log n).
+func (t *RBTreeOf[T]) Rank(item T) (rank int) {
+	x := t.root
+	for x != nil {
+		switch t.compare(x.Elem, item) {
+		case EQ:
+			return rank + x.left.sizeOf()
+		case GT:
+			x = x.left
+		case LT:
+			rank += 1 + x.left.sizeOf()
+			x = x.right
+		}
+	}
+	return
+}
+
+// Select returns the k-th smallest element, counting from zero. If k is not
+// within [0, Size()), then ok is false. Runs in O(log n).
+func (t *RBTreeOf[T]) Select(k int) (found T, ok bool) {
+	if k < 0 || k >= t.size {
+		return
+	}
+	x := t.root
+	for x != nil {
+		switch left := x.left.sizeOf(); {
+		case k < left:
+			x = x.left
+		case k > left:
+			k -= left + 1
+			x = x.right
+		default:
+			return x.Elem, true
+		}
+	}
+	return
+}
+
+// CountRange returns the number of elements e such that lo <= e <= hi.
+// Runs in O(log n).
+func (t *RBTreeOf[T]) CountRange(lo, hi T) int {
+	if t.compare(lo, hi) == GT {
+		return 0
+	}
+	count := t.Rank(hi) - t.Rank(lo)
+	if _, ok := t.Search(hi); ok {
+		count++
+	}
+	return count
+}
+
+// Min returns the smallest inserted element if possible. If the smallest value is not
+// found(empty tree), then Min returns a nil.
+func (t *RBTree) Min() Interface {
+	min, _ := t.RBTreeOf.Min()
+	return min
+}
+
+// Max returns the largest inserted element if possible. If the largest value is not
+// found(empty tree), then Max returns a nil.
+func (t *RBTree) Max() Interface {
+	max, _ := t.RBTreeOf.Max()
+	return max
+}
+
+// Next is called when individual elements are wanted to be traversed over.
+// Prior to a call to Next, a call to IterInit needs to be made to set up the necessary
+// data to allow for traversal of the tree. Example:
+//
+//    sum := 0
+//    for i, n := 0, tree.IterInit(InOrder); n != nil; i, n = i+1, tree.Next() {
+//        elem := n.(exInt)  // (exInt is simple int type)
+//        sum += int(elem) + i
+//    }
+// Note: If one was to break out of the loop prior to a complete traversal,
+// and start another loop without calling IterInit, then the previously uncompleted iterator is continued again.
+func (t *RBTree) Next() Interface {
+	next, _ := t.RBTreeOf.Next()
+	return next
+}
+
+// IterInit is the initializer which setups the tree for iterating over it's elements in
+// a specific order. It setups the internal data, and then returns the first RBNode to be looked at. See Next for an example.
+func (t *RBTree) IterInit(order TravOrder) Interface {
+	first, _ := t.RBTreeOf.IterInit(order)
+	return first
+}
+
+// Map is a more performance orientated way to iterate over the elements of the tree.
+// Given a TravOrder and a function which conforms to the IterFunc type:
+//
+//      type IterFunc func(Interface)
+//
+// Map calls the function for each RBNode  in the specified order.
+func (t *RBTree) Map(order TravOrder, f IterFunc) {
+	t.RBTreeOf.Map(order, f)
+}
+
+// IterRangeInit is like IterInit, but only the elements between lo and hi are visited. Bounds
+// chooses whether lo and hi themselves are included. Only InOrder and RevOrder are possible, with
+// RevOrder starting from hi. See Next for an example.
+func (t *RBTree) IterRangeInit(order TravOrder, lo, hi Interface, bounds Bounds) Interface {
+	if lo == nil || hi == nil {
+		t.iterNext = nil
+		return nil
+	}
+	first, _ := t.RBTreeOf.IterRangeInit(order, lo, hi, bounds)
+	return first
+}
+
+// MapRange is like Map, but only the elements between lo and hi are visited. Bounds
+// chooses whether lo and hi themselves are included. Only InOrder and RevOrder are possible.
+func (t *RBTree) MapRange(order TravOrder, lo, hi Interface, bounds Bounds, f IterFunc) {
+	if lo == nil || hi == nil {
+		return
+	}
+	t.RBTreeOf.MapRange(order, lo, hi, bounds, f)
+}
+
+// Cursor returns a new unpositioned cursor over the tree. See CursorOf for an example.
+func (t *RBTree) Cursor() Cursor {
+	return cursor{t.RBTreeOf.Cursor()}
+}
+
+// Search returns the matching item if found, otherwise nil is returned.
+func (t *RBTree) Search(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.RBTreeOf.Search(item)
+	return
+}
+
+// Insert will either insert a new entry into the tree, and return nil. Or if there was a previous entry already inserted, then in addition to inserting the new item, the previously inserted item will be returned.
+func (t *RBTree) Insert(item Interface) (old Interface) {
+	if item == nil {
+		return
+	}
+	old, _ = t.RBTreeOf.Insert(item)
+	return
+}
+
+// Remove looks for a matching entry, and if found, the item is removed from the tree and old is populated with the removed item. If the item is not matched in the tree, nil is returned.
+func (t *RBTree) Remove(item Interface) (old Interface) {
+	if item == nil {
+		return
+	}
+	old, _ = t.RBTreeOf.Remove(item)
+	return
+}
+
+// Floor returns the largest element less than or equal to item, if there is none Floor returns a nil.
+func (t *RBTree) Floor(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.RBTreeOf.Floor(item)
+	return
+}
+
+// Ceiling returns the smallest element greater than or equal to item, if there is none Ceiling returns a nil.
+func (t *RBTree) Ceiling(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.RBTreeOf.Ceiling(item)
+	return
+}
+
+// Predecessor returns the largest element strictly less than item, if there is none Predecessor returns a nil.
+func (t *RBTree) Predecessor(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.RBTreeOf.Predecessor(item)
+	return
+}
+
+// Successor returns the smallest element strictly greater than item, if there is none Successor returns a nil.
+func (t *RBTree) Successor(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.RBTreeOf.Successor(item)
+	return
+}
+
+// Rank returns the number of elements in the tree which are less than item.
+// When item is in the tree this is its index within an InOrder traversal.
+func (t *RBTree) Rank(item Interface) int {
+	if item == nil {
+		return 0
+	}
+	return t.RBTreeOf.Rank(item)
+}
+
+// Select returns the k-th smallest element, counting from zero. If k is not
+// within [0, Size()), then Select returns a nil.
+func (t *RBTree) Select(k int) Interface {
+	found, _ := t.RBTreeOf.Select(k)
+	return found
+}
+
+// CountRange returns the number of elements e such that lo <= e <= hi.
+func (t *RBTree) CountRange(lo, hi Interface) int {
+	if lo == nil || hi == nil {
+		return 0
+	}
+	return t.RBTreeOf.CountRange(lo, hi)
+}
+
 // Left Leaning red black Tree functions and helpers to maintain public methods
 
-func (h *RBNode) rotateLeft() (x *RBNode) {
+func (h *RBNodeOf[T]) rotateLeft() (x *RBNodeOf[T]) {
 	x = h.right
 	h.right = x.left
 	x.left = h
 	x.color = h.color
 	h.color = red
+	x.size = h.size
+	h.resize()
 	return
 }
 
-func (h *RBNode) rotateRight() (x *RBNode) {
+func (h *RBNodeOf[T]) rotateRight() (x *RBNodeOf[T]) {
 	x = h.left
 	h.left = x.right
 	x.right = h
 	x.color = h.color
 	h.color = red
+	x.size = h.size
+	h.resize()
 	return
 }
 
-func (h *RBNode) isred() bool {
+// subtree size, nil being an empty subtree
+func (h *RBNodeOf[T]) sizeOf() int {
+	if h == nil {
+		return 0
+	}
+	return h.size
+}
+
+// recompute size from our children, which must already be correct
+func (h *RBNodeOf[T]) resize() {
+	h.size = 1 + h.left.sizeOf() + h.right.sizeOf()
+}
+
+func (h *RBNodeOf[T]) isred() bool {
 	return h != nil && h.color == red
 }
 
-func (h *RBNode) moveredLeft() *RBNode {
+func (h *RBNodeOf[T]) moveredLeft() *RBNodeOf[T] {
 	h.colorFlip()
 	if h.right.left.isred() {
 		h.right = h.right.rotateRight()
@@ -469,7 +1124,7 @@ func (h *RBNode) moveredLeft() *RBNode {
 	return h
 }
 
-func (h *RBNode) moveredRight() *RBNode {
+func (h *RBNodeOf[T]) moveredRight() *RBNodeOf[T] {
 	h.colorFlip()
 	if h.left.left.isred() {
 		h = h.rotateRight()
@@ -478,13 +1133,14 @@ func (h *RBNode) moveredRight() *RBNode {
 	return h
 }
 
-func (h *RBNode) colorFlip() {
+func (h *RBNodeOf[T]) colorFlip() {
 	h.color = !h.color
 	h.left.color = !h.left.color
 	h.right.color = !h.right.color
 }
 
-func (h *RBNode) fixUp() *RBNode {
+func (h *RBNodeOf[T]) fixUp() *RBNodeOf[T] {
+	h.resize()
 	if h.right.isred() {
 		h = h.rotateLeft()
 	}
@@ -497,18 +1153,18 @@ func (h *RBNode) fixUp() *RBNode {
 	}
 	return h
 }
-func (h *RBNode) min() *RBNode {
+func (h *RBNodeOf[T]) min() *RBNodeOf[T] {
 	for ; h.left != nil; h = h.left {
 	}
 	return h
 }
-func (h *RBNode) max() *RBNode {
+func (h *RBNodeOf[T]) max() *RBNodeOf[T] {
 	for ; h.right != nil; h = h.right {
 	}
 	return h
 }
 
-func (h *RBNode) removeMin() *RBNode {
+func (h *RBNodeOf[T]) removeMin() *RBNodeOf[T] {
 	if h.left == nil {
 		return nil
 	}
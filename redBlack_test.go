@@ -32,6 +32,14 @@ func nodeIsBalanced(n *RBNode, h int) bool {
 	return nodeIsBalanced(n.left, h) && nodeIsBalanced(n.right, h)
 }
 
+func nodeSizeIsConsistent(n *RBNode) bool {
+	if n == nil {
+		return true
+	}
+	return n.size == 1+n.left.sizeOf()+n.right.sizeOf() &&
+		nodeSizeIsConsistent(n.left) && nodeSizeIsConsistent(n.right)
+}
+
 // remove, min and max tests
 func TestRBRemove(t *testing.T) {
 
@@ -124,6 +132,66 @@ func TestRBRandomRemove(t *testing.T) {
 	}
 }
 
+// order statistic tests
+func TestRBOrderStatistics(t *testing.T) {
+
+	tree := &RBTree{}
+	if check := tree.Select(0); check != nil {
+		t.Errorf("Not minding empty tree")
+	}
+	if rank := tree.Rank(exInt(5)); rank != 0 {
+		t.Errorf("Not minding empty tree")
+	}
+	r := rand.New(rand.NewSource(int64(5)))
+	m := make(map[int]int)
+	for i := 0; i < iters; i++ {
+		a := r.Intn(searchSpace)
+		m[a] = a
+		tree.Insert(exInt(a))
+	}
+	if !nodeSizeIsConsistent(tree.root) || tree.root.size != tree.Size() {
+		t.Errorf("Subtree sizes not maintained on insert")
+	}
+
+	i := 0
+	tree.Map(InOrder, func(n Interface) {
+		if rank := tree.Rank(n); rank != i {
+			t.Errorf("Wrong rank Got: %d, Exp: %d", rank, i)
+		}
+		if check := tree.Select(i); check != n {
+			t.Errorf("Wrong select Got: %v, Exp: %v", check, n)
+		}
+		i++
+	})
+	if check := tree.Select(tree.Size()); check != nil {
+		t.Errorf("Should not select past the end")
+	}
+	if check := tree.Select(-1); check != nil {
+		t.Errorf("Should not select before the start")
+	}
+
+	for k := 0; k < 100; k++ {
+		lo, hi := r.Intn(searchSpace), r.Intn(searchSpace)
+		count := 0
+		for _, v := range m {
+			if v >= lo && v <= hi {
+				count++
+			}
+		}
+		if check := tree.CountRange(exInt(lo), exInt(hi)); check != count {
+			t.Errorf("Wrong range count Got: %d, Exp: %d", check, count)
+		}
+	}
+
+	for _, value := range m {
+		tree.Remove(exInt(value))
+		if !nodeSizeIsConsistent(tree.root) {
+			t.Errorf("Subtree sizes not maintained on remove")
+			break
+		}
+	}
+}
+
 // iteration and map tests
 func TestIterRBIn(t *testing.T) {
 
@@ -2,24 +2,50 @@ package gotree
 
 import (
 	"fmt"
+	"iter"
+	"math/rand"
 	"runtime"
 )
 
 var _ = fmt.Printf
 
-type SplayNode struct {
-	Elem        Interface
-	left, right *SplayNode
+type SplayNodeOf[T any] struct {
+	Elem        T
+	left, right *SplayNodeOf[T]
 }
 
-type SplayTree struct {
+// A SplayNode is the node type of the Interface based SplayTree.
+type SplayNode = SplayNodeOf[Interface]
+
+// A SplayTreeOf is the type parameterized splay tree, elements are ordered by its CompareFunc.
+// The zero value uses the elements own Compare method, and so is only usable for types implementing Interface.
+type SplayTreeOf[T any] struct {
 	size        int // Number of inserted elements
-	first, last *SplayNode
-	iterNext    func() Interface // initially nil
-	root        *SplayNode
+	first, last *SplayNodeOf[T]
+	iterNext    func() (T, bool) // initially nil
+	root        *SplayNodeOf[T]
+	cmp         CompareFunc[T]
+	rand        *rand.Rand // source for RandOrder, nil for the global source
+}
+
+// NewSplayTreeOf returns an empty tree which orders its elements using cmp.
+func NewSplayTreeOf[T any](cmp CompareFunc[T]) *SplayTreeOf[T] {
+	return &SplayTreeOf[T]{cmp: cmp}
 }
 
-func (t *SplayTree) Clear() {
+// A SplayTree is a thin wrapper around SplayTreeOf which uses nil to signal a missing element.
+type SplayTree struct {
+	SplayTreeOf[Interface]
+}
+
+func (t *SplayTreeOf[T]) compare(a, b T) Balance {
+	if t.cmp == nil {
+		return compareInterface(a, b)
+	}
+	return t.cmp(a, b)
+}
+
+func (t *SplayTreeOf[T]) Clear() {
 	t.root = nil
 	t.last = nil
 	t.first = nil
@@ -28,93 +54,89 @@ func (t *SplayTree) Clear() {
 	runtime.GC()
 }
 
-// Search returns the matching item if found, otherwise nil is returned.
-func (t *SplayTree) Search(item Interface) (found Interface) {
-	if item == nil || t.root == nil {
+// Search returns the matching item if found, otherwise ok is false.
+func (t *SplayTreeOf[T]) Search(item T) (found T, ok bool) {
+	if t.root == nil {
 		return
 	}
-	t.root = t.root.splay(item)
-	switch t.root.Elem.Compare(item) {
+	t.root = t.splay(t.root, item)
+	switch t.compare(t.root.Elem, item) {
 	case EQ:
-		return t.root.Elem
+		return t.root.Elem, true
 	}
 	return
 }
 
-// Insert will either insert a new entry into the tree, and return nil. Or if there was a previous entry already inserted, then in addition to inserting the new item, the previously inserted item will be returned.
-func (t *SplayTree) Insert(item Interface) (old Interface) {
-	var n *SplayNode
-	// TODO min and max update
-	if item == nil {
-		return nil
-	}
+// Insert will either insert a new entry into the tree, and return with ok being false. Or if there was a previous entry already inserted, then in addition to inserting the new item, the previously inserted item will be returned.
+func (t *SplayTreeOf[T]) Insert(item T) (old T, ok bool) {
+	var n *SplayNodeOf[T]
 
 	if t.root == nil {
 		t.size++
-		t.root = &SplayNode{Elem: item, left: nil, right: nil}
+		t.root = &SplayNodeOf[T]{Elem: item, left: nil, right: nil}
 		t.first = t.root
 		t.last = t.root
 		return
 	}
-	t.root = t.root.splay(item)
-	switch t.root.Elem.Compare(item) {
+	t.root = t.splay(t.root, item)
+	switch t.compare(t.root.Elem, item) {
 	case GT:
-		n = &SplayNode{Elem: item, left: t.root.left, right: t.root}
+		n = &SplayNodeOf[T]{Elem: item, left: t.root.left, right: t.root}
 		t.root.left = nil
 		t.root = n
 		t.size++
 	case LT:
-		n = &SplayNode{Elem: item, left: t.root, right: t.root.right}
+		n = &SplayNodeOf[T]{Elem: item, left: t.root, right: t.root.right}
 		t.root.right = nil
 		t.root = n
 		t.size++
 	case EQ:
-		old = t.root.Elem
+		old, ok = t.root.Elem, true
 		t.root.Elem = item
 
 	}
 	// set Min
-	switch t.first.Elem.Compare(item) {
+	switch t.compare(t.first.Elem, item) {
 	case GT:
 		t.first = n
 	}
 	// set Max
-	switch t.last.Elem.Compare(item) {
+	switch t.compare(t.last.Elem, item) {
 	case LT:
 		t.last = n
 	}
 	return
 }
 
-// Remove looks for a matching entry, and if found, the item is removed from the tree and old is populated with the removed item. If the item is not matched in the tree, nil is returned.
-func (t *SplayTree) Remove(item Interface) (old Interface) {
-	var x *SplayNode
-	if item == nil || t.root == nil {
+// Remove looks for a matching entry, and if found, the item is removed from the tree and old is populated with the removed item. If the item is not matched in the tree, ok is false.
+func (t *SplayTreeOf[T]) Remove(item T) (old T, ok bool) {
+	var x *SplayNodeOf[T]
+	if t.root == nil {
 		return
 	}
 
-	t.root = t.root.splay(item)
+	t.root = t.splay(t.root, item)
 
-	switch t.root.Elem.Compare(item) {
+	switch t.compare(t.root.Elem, item) {
 	// TODO NP case
 	case EQ:
-		old = t.root.Elem
+		old, ok = t.root.Elem, true
 		if t.root.left == nil {
 			x = t.root.right
 		} else {
-			x = t.root.left.splay(item)
+			x = t.splay(t.root.left, item)
 			x.right = t.root.right
 		}
 		t.root = x
 		t.size--
 		if t.root != nil {
 			// set Min
-			switch t.first.Elem.Compare(old) {
+			switch t.compare(t.first.Elem, old) {
 			case EQ:
 				t.first = t.root.min()
 			}
 			// set Max
-			switch t.last.Elem.Compare(old) {
+			switch t.compare(t.last.Elem, old) {
 			case EQ:
 				t.last = t.root.max()
 			}
@@ -124,26 +146,41 @@ func (t *SplayTree) Remove(item Interface) (old Interface) {
 		}
 
 	}
-	return old
+	return
 
 }
 
+// SetRand chooses the source of randomness used by RandOrder traversals, nil being the global
+// math/rand source. A *rand.Rand is not safe for concurrent use, so neither are RandOrder traversals sharing one.
+func (t *SplayTreeOf[T]) SetRand(r *rand.Rand) {
+	t.rand = r
+}
+
+// all the elements, for the orders which must see everything before starting
+func (t *SplayTreeOf[T]) elems() []T {
+	elems := make([]T, 0, t.size)
+	t.Map(InOrder, func(elem T) {
+		elems = append(elems, elem)
+	})
+	return elems
+}
+
 // Min returns the smallest inserted element if possible. If the smallest value is not
-// found(empty tree), then Min returns a nil.
-func (t *SplayTree) Min() Interface {
+// found(empty tree), then ok is false.
+func (t *SplayTreeOf[T]) Min() (min T, ok bool) {
 	if t.first != nil {
-		return t.first.Elem
+		return t.first.Elem, true
 	}
-	return nil
+	return
 }
 
 // Max returns the largest inserted element if possible. If the largest value is not
-// found(empty tree), then Max returns a nil.
-func (t *SplayTree) Max() Interface {
+// found(empty tree), then ok is false.
+func (t *SplayTreeOf[T]) Max() (max T, ok bool) {
 	if t.last != nil {
-		return t.last.Elem
+		return t.last.Elem, true
 	}
-	return nil
+	return
 }
 
 // Next is called when individual elements are wanted to be traversed over.
@@ -151,30 +188,29 @@ func (t *SplayTree) Max() Interface {
 // data to allow for traversal of the tree. Example:
 //
 //    sum := 0
-//    for i, n := 0, tree.IterInit(InOrder); n != nil; i, n = i+1, tree.Next() {
-//        elem := n.(exInt)  // (exInt is simple int type)
-//        sum += int(elem) + i
+//    for n, ok := tree.IterInit(InOrder); ok; n, ok = tree.Next() {
+//        sum += n
 //    }
 // Note: If one was to break out of the loop prior to a complete traversal,
 // and start another loop without calling IterInit, then the previously uncompleted iterator is continued again.
-func (t *SplayTree) Next() Interface {
+func (t *SplayTreeOf[T]) Next() (next T, ok bool) {
 
 	if t.iterNext == nil {
-		return nil
+		return
 	}
 	return t.iterNext() // func set by call to IterInit(TravOrder)
 
 }
 
 // IterInit is the initializer which setups the tree for iterating over it's elements in
-// a specific order. It setups the internal data, and then returns the first Interface to be looked at. See Next for an example.
-func (t *SplayTree) IterInit(order TravOrder) Interface {
+// a specific order. It setups the internal data, and then returns the first element to be looked at. See Next for an example.
+func (t *SplayTreeOf[T]) IterInit(order TravOrder) (T, bool) {
 
 	current := t.root
-	stack := []*SplayNode{}
+	stack := []*SplayNodeOf[T]{}
 	switch order {
-	case InOrder:
-		t.iterNext = func() (out Interface) {
+	case InOrder, AnyOrder:
+		t.iterNext = func() (out T, ok bool) {
 			for len(stack) > 0 || current != nil {
 				if current != nil {
 					stack = append(stack, current)
@@ -183,21 +219,21 @@ func (t *SplayTree) IterInit(order TravOrder) Interface {
 					// pop
 					stackIndex := len(stack) - 1
 					current = stack[stackIndex]
-					out = current.Elem
+					out, ok = current.Elem, true
 					stack = stack[0:stackIndex]
 					current = current.right
 					break
 				}
 			}
 			// last node, reset
-			if out == nil {
+			if !ok {
 				t.iterNext = nil
 			}
-			return out
+			return
 		}
 
 	case RevOrder:
-		t.iterNext = func() (out Interface) {
+		t.iterNext = func() (out T, ok bool) {
 			for len(stack) > 0 || current != nil {
 				if current != nil {
 					stack = append(stack, current)
@@ -206,17 +242,115 @@ func (t *SplayTree) IterInit(order TravOrder) Interface {
 					// pop
 					stackIndex := len(stack) - 1
 					current = stack[stackIndex]
-					out = current.Elem
+					out, ok = current.Elem, true
 					stack = stack[0:stackIndex]
 					current = current.left
 					break
 				}
 			}
 			// last node, reset
-			if out == nil {
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+		}
+
+	case PreOrder:
+		t.iterNext = func() (out T, ok bool) {
+			for len(stack) > 0 || current != nil {
+				if current != nil {
+					out, ok = current.Elem, true
+					stack = append(stack, current.right)
+					current = current.left
+					break
+				} else {
+					// pop
+					stackIndex := len(stack) - 1
+					current = stack[stackIndex]
+					stack = stack[0:stackIndex]
+				}
+			}
+
+			// last node, reset
+			if !ok {
 				t.iterNext = nil
 			}
-			return out
+			return
+		}
+	case PostOrder:
+		if current != nil {
+			stack = append(stack, current)
+		}
+		var prevSplayNode *SplayNodeOf[T]
+
+		t.iterNext = func() (out T, ok bool) {
+			for len(stack) > 0 {
+				// peek
+				stackIndex := len(stack) - 1
+				current = stack[stackIndex]
+				if (prevSplayNode == nil) ||
+					(prevSplayNode.left == current) ||
+					(prevSplayNode.right == current) {
+					if current.left != nil {
+						stack = append(stack, current.left)
+					} else if current.right != nil {
+						stack = append(stack, current.right)
+					}
+				} else if current.left == prevSplayNode {
+					if current.right != nil {
+						stack = append(stack, current.right)
+					}
+				} else {
+					out, ok = current.Elem, true
+					// pop, but no assignment
+					stackIndex := len(stack) - 1
+					stack = stack[0:stackIndex]
+					prevSplayNode = current
+					break
+				}
+				prevSplayNode = current
+			}
+
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+
+		}
+	case LevelOrder:
+		queue := []*SplayNodeOf[T]{}
+		if current != nil {
+			queue = append(queue, current)
+		}
+		t.iterNext = func() (out T, ok bool) {
+			if len(queue) > 0 {
+				// dequeue
+				current = queue[0]
+				queue = queue[1:]
+				out, ok = current.Elem, true
+				if current.left != nil {
+					queue = append(queue, current.left)
+				}
+				if current.right != nil {
+					queue = append(queue, current.right)
+				}
+			}
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+		}
+	case RandOrder:
+		next := shuffle(t.elems(), t.rand)
+		t.iterNext = func() (out T, ok bool) {
+			out, ok = next()
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
 		}
 	default:
 		s := fmt.Sprintf("rbSplayTree has not implemented %s for iteration.", order)
@@ -228,21 +362,18 @@ func (t *SplayTree) IterInit(order TravOrder) Interface {
 }
 
 // Map is a more performance orientated way to iterate over the elements of the tree.
-// Given a TravOrder and a function which conforms to the IterFunc type:
-//
-//      type IterFunc func(Interface)
-//
-// Map calls the function for each Interface type in the specified order.
-func (t *SplayTree) Map(order TravOrder, f IterFunc) {
+// Given a TravOrder and a function to call with each element,
+// Map calls the function for each element in the specified order.
+func (t *SplayTreeOf[T]) Map(order TravOrder, f func(T)) {
 
 	if t.root == nil {
 		return
 	}
 	n := t.root
 	switch order {
-	case InOrder:
-		var inorder func(node *SplayNode)
-		inorder = func(node *SplayNode) {
+	case InOrder, AnyOrder:
+		var inorder func(node *SplayNodeOf[T])
+		inorder = func(node *SplayNodeOf[T]) {
 			if node == nil {
 				return
 			}
@@ -251,6 +382,51 @@ func (t *SplayTree) Map(order TravOrder, f IterFunc) {
 			inorder(node.right)
 		}
 		inorder(n)
+	case RevOrder:
+		var revorder func(node *SplayNodeOf[T])
+		revorder = func(node *SplayNodeOf[T]) {
+			if node == nil {
+				return
+			}
+			revorder(node.right)
+			f(node.Elem)
+			revorder(node.left)
+		}
+		revorder(n)
+	case PreOrder:
+		var preorder func(node *SplayNodeOf[T])
+		preorder = func(node *SplayNodeOf[T]) {
+			if node == nil {
+				return
+			}
+			f(node.Elem)
+			preorder(node.left)
+			preorder(node.right)
+		}
+		preorder(n)
+	case PostOrder:
+		var postorder func(node *SplayNodeOf[T])
+		postorder = func(node *SplayNodeOf[T]) {
+			if node == nil {
+				return
+			}
+			postorder(node.left)
+			postorder(node.right)
+			f(node.Elem)
+		}
+		postorder(n)
+	case LevelOrder:
+		for queue := []*SplayNodeOf[T]{n}; len(queue) > 0; queue = queue[1:] {
+			if node := queue[0]; node != nil {
+				f(node.Elem)
+				queue = append(queue, node.left, node.right)
+			}
+		}
+	case RandOrder:
+		next := shuffle(t.elems(), t.rand)
+		for elem, ok := next(); ok; elem, ok = next() {
+			f(elem)
+		}
 	default:
 		s := fmt.Sprintf("SplayTree has not implemented %s.", order)
 		panic(s)
@@ -258,16 +434,495 @@ func (t *SplayTree) Map(order TravOrder, f IterFunc) {
 
 }
 
+// All returns an iterator over the elements of the tree in the given order, for use with range:
+//
+//    for elem := range tree.All(InOrder) {
+//        fmt.Println(elem)
+//    }
+// Breaking out of the loop early is fine, as no state is kept within the tree.
+func (t *SplayTreeOf[T]) All(order TravOrder) iter.Seq[T] {
+	var walk func(node *SplayNodeOf[T], yield func(T) bool) bool
+	switch order {
+	case InOrder, AnyOrder:
+		walk = func(node *SplayNodeOf[T], yield func(T) bool) bool {
+			return node == nil ||
+				walk(node.left, yield) && yield(node.Elem) && walk(node.right, yield)
+		}
+	case RevOrder:
+		walk = func(node *SplayNodeOf[T], yield func(T) bool) bool {
+			return node == nil ||
+				walk(node.right, yield) && yield(node.Elem) && walk(node.left, yield)
+		}
+	case PreOrder:
+		walk = func(node *SplayNodeOf[T], yield func(T) bool) bool {
+			return node == nil ||
+				yield(node.Elem) && walk(node.left, yield) && walk(node.right, yield)
+		}
+	case PostOrder:
+		walk = func(node *SplayNodeOf[T], yield func(T) bool) bool {
+			return node == nil ||
+				walk(node.left, yield) && walk(node.right, yield) && yield(node.Elem)
+		}
+	case LevelOrder:
+		return func(yield func(T) bool) {
+			for queue := []*SplayNodeOf[T]{t.root}; len(queue) > 0; queue = queue[1:] {
+				if node := queue[0]; node != nil {
+					if !yield(node.Elem) {
+						return
+					}
+					queue = append(queue, node.left, node.right)
+				}
+			}
+		}
+	case RandOrder:
+		return func(yield func(T) bool) {
+			next := shuffle(t.elems(), t.rand)
+			for elem, ok := next(); ok && yield(elem); elem, ok = next() {
+			}
+		}
+	default:
+		s := fmt.Sprintf("SplayTree has not implemented %s for iteration.", order)
+		panic(s)
+	}
+	return func(yield func(T) bool) {
+		walk(t.root, yield)
+	}
+}
+
+// IterRangeInit is like IterInit, but only the elements between lo and hi are visited. Bounds
+// chooses whether lo and hi themselves are included. Only InOrder and RevOrder are possible, with
+// RevOrder starting from hi. The traversal descends directly to its starting bound, so the cost is
+// that of a search plus the number of elements visited. See Next for an example.
+func (t *SplayTreeOf[T]) IterRangeInit(order TravOrder, lo, hi T, bounds Bounds) (T, bool) {
+
+	stack := []*SplayNodeOf[T]{}
+	switch order {
+	case InOrder:
+		// path down to the smallest element within the lower bound
+		for current := t.root; current != nil; {
+			if bounds.afterLo(t.compare(current.Elem, lo)) {
+				stack = append(stack, current)
+				current = current.left
+			} else {
+				current = current.right
+			}
+		}
+		t.iterNext = func() (out T, ok bool) {
+			if len(stack) > 0 {
+				// pop
+				stackIndex := len(stack) - 1
+				current := stack[stackIndex]
+				stack = stack[0:stackIndex]
+				if bounds.beforeHi(t.compare(current.Elem, hi)) {
+					out, ok = current.Elem, true
+					for current = current.right; current != nil; current = current.left {
+						stack = append(stack, current)
+					}
+				}
+			}
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+		}
+	case RevOrder:
+		// path down to the largest element within the upper bound
+		for current := t.root; current != nil; {
+			if bounds.beforeHi(t.compare(current.Elem, hi)) {
+				stack = append(stack, current)
+				current = current.right
+			} else {
+				current = current.left
+			}
+		}
+		t.iterNext = func() (out T, ok bool) {
+			if len(stack) > 0 {
+				// pop
+				stackIndex := len(stack) - 1
+				current := stack[stackIndex]
+				stack = stack[0:stackIndex]
+				if bounds.afterLo(t.compare(current.Elem, lo)) {
+					out, ok = current.Elem, true
+					for current = current.left; current != nil; current = current.right {
+						stack = append(stack, current)
+					}
+				}
+			}
+			// last node, reset
+			if !ok {
+				t.iterNext = nil
+			}
+			return
+		}
+	default:
+		s := fmt.Sprintf("SplayTree has not implemented %s for range iteration.", order)
+		panic(s)
+	}
+	// return our first node
+	return t.iterNext()
+}
+
+// MapRange is like Map, but only the elements between lo and hi are visited. Bounds
+// chooses whether lo and hi themselves are included. Only InOrder and RevOrder are possible.
+// Subtrees entirely outside of the range are never entered.
+func (t *SplayTreeOf[T]) MapRange(order TravOrder, lo, hi T, bounds Bounds, f func(T)) {
+
+	n := t.root
+	switch order {
+	case InOrder:
+		var inorder func(node *SplayNodeOf[T])
+		inorder = func(node *SplayNodeOf[T]) {
+			if node == nil {
+				return
+			}
+			afterLo := bounds.afterLo(t.compare(node.Elem, lo))
+			beforeHi := bounds.beforeHi(t.compare(node.Elem, hi))
+			if afterLo {
+				inorder(node.left)
+			}
+			if afterLo && beforeHi {
+				f(node.Elem)
+			}
+			if beforeHi {
+				inorder(node.right)
+			}
+		}
+		inorder(n)
+	case RevOrder:
+		var revorder func(node *SplayNodeOf[T])
+		revorder = func(node *SplayNodeOf[T]) {
+			if node == nil {
+				return
+			}
+			afterLo := bounds.afterLo(t.compare(node.Elem, lo))
+			beforeHi := bounds.beforeHi(t.compare(node.Elem, hi))
+			if beforeHi {
+				revorder(node.right)
+			}
+			if afterLo && beforeHi {
+				f(node.Elem)
+			}
+			if afterLo {
+				revorder(node.left)
+			}
+		}
+		revorder(n)
+	default:
+		s := fmt.Sprintf("SplayTree has not implemented %s for range mapping.", order)
+		panic(s)
+	}
+}
+
+// splayCursor is the CursorOf a SplayTreeOf, it holds the path from the root down to its current node.
+// Unlike Search, moving a cursor never splays, so the tree is left untouched.
+type splayCursor[T any] struct {
+	tree *SplayTreeOf[T]
+	path []*SplayNodeOf[T] // empty when not positioned
+}
+
+// Cursor returns a new unpositioned cursor over the tree. See CursorOf for an example.
+func (t *SplayTreeOf[T]) Cursor() CursorOf[T] {
+	return &splayCursor[T]{tree: t}
+}
+
+func (c *splayCursor[T]) Elem() (elem T, ok bool) {
+	if len(c.path) == 0 {
+		return
+	}
+	return c.path[len(c.path)-1].Elem, true
+}
+
+func (c *splayCursor[T]) Next() bool {
+	if len(c.path) == 0 {
+		// start from the smallest
+		for x := c.tree.root; x != nil; x = x.left {
+			c.path = append(c.path, x)
+		}
+		return len(c.path) > 0
+	}
+	if x := c.path[len(c.path)-1].right; x != nil {
+		// smallest of the right subtree
+		for ; x != nil; x = x.left {
+			c.path = append(c.path, x)
+		}
+		return true
+	}
+	// go up until we leave a left subtree
+	for {
+		child := c.path[len(c.path)-1]
+		c.path = c.path[:len(c.path)-1]
+		if len(c.path) == 0 {
+			return false
+		}
+		if c.path[len(c.path)-1].left == child {
+			return true
+		}
+	}
+}
+
+func (c *splayCursor[T]) Prev() bool {
+	if len(c.path) == 0 {
+		// start from the largest
+		for x := c.tree.root; x != nil; x = x.right {
+			c.path = append(c.path, x)
+		}
+		return len(c.path) > 0
+	}
+	if x := c.path[len(c.path)-1].left; x != nil {
+		// largest of the left subtree
+		for ; x != nil; x = x.right {
+			c.path = append(c.path, x)
+		}
+		return true
+	}
+	// go up until we leave a right subtree
+	for {
+		child := c.path[len(c.path)-1]
+		c.path = c.path[:len(c.path)-1]
+		if len(c.path) == 0 {
+			return false
+		}
+		if c.path[len(c.path)-1].right == child {
+			return true
+		}
+	}
+}
+
+func (c *splayCursor[T]) Seek(item T) bool {
+	c.path = c.path[:0]
+	// depth of the smallest node found so far which is greater than item
+	ceiling := 0
+	for x := c.tree.root; x != nil; {
+		c.path = append(c.path, x)
+		switch c.tree.compare(x.Elem, item) {
+		case EQ:
+			return true
+		case GT:
+			ceiling = len(c.path)
+			x = x.left
+		case LT:
+			x = x.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return ceiling > 0
+}
+
+// Floor returns the largest element less than or equal to item, if there is none ok is false.
+// The found element is splayed to the root.
+func (t *SplayTreeOf[T]) Floor(item T) (found T, ok bool) {
+	return t.below(item, true)
+}
+
+// Ceiling returns the smallest element greater than or equal to item, if there is none ok is false.
+// The found element is splayed to the root.
+func (t *SplayTreeOf[T]) Ceiling(item T) (found T, ok bool) {
+	return t.above(item, true)
+}
+
+// Predecessor returns the largest element strictly less than item, if there is none ok is false.
+// The found element is splayed to the root.
+func (t *SplayTreeOf[T]) Predecessor(item T) (found T, ok bool) {
+	return t.below(item, false)
+}
+
+// Successor returns the smallest element strictly greater than item, if there is none ok is false.
+// The found element is splayed to the root.
+func (t *SplayTreeOf[T]) Successor(item T) (found T, ok bool) {
+	return t.above(item, false)
+}
+
+// below finds the closest element less than item, and equal to it as well when inclusive.
+func (t *SplayTreeOf[T]) below(item T, inclusive bool) (found T, ok bool) {
+	if t.root == nil {
+		return
+	}
+	// after splaying the root is either item or one of its two neighbors
+	t.root = t.splay(t.root, item)
+	switch t.compare(t.root.Elem, item) {
+	case LT:
+		return t.root.Elem, true
+	case EQ:
+		if inclusive {
+			return t.root.Elem, true
+		}
+	}
+	if t.root.left == nil {
+		return
+	}
+	t.root = t.splay(t.root, t.root.left.max().Elem)
+	return t.root.Elem, true
+}
+
+// above finds the closest element greater than item, and equal to it as well when inclusive.
+func (t *SplayTreeOf[T]) above(item T, inclusive bool) (found T, ok bool) {
+	if t.root == nil {
+		return
+	}
+	// after splaying the root is either item or one of its two neighbors
+	t.root = t.splay(t.root, item)
+	switch t.compare(t.root.Elem, item) {
+	case GT:
+		return t.root.Elem, true
+	case EQ:
+		if inclusive {
+			return t.root.Elem, true
+		}
+	}
+	if t.root.right == nil {
+		return
+	}
+	t.root = t.splay(t.root, t.root.right.min().Elem)
+	return t.root.Elem, true
+}
+
+// Min returns the smallest inserted element if possible. If the smallest value is not
+// found(empty tree), then Min returns a nil.
+func (t *SplayTree) Min() Interface {
+	min, _ := t.SplayTreeOf.Min()
+	return min
+}
+
+// Max returns the largest inserted element if possible. If the largest value is not
+// found(empty tree), then Max returns a nil.
+func (t *SplayTree) Max() Interface {
+	max, _ := t.SplayTreeOf.Max()
+	return max
+}
+
+// Next is called when individual elements are wanted to be traversed over.
+// Prior to a call to Next, a call to IterInit needs to be made to set up the necessary
+// data to allow for traversal of the tree. Example:
+//
+//    sum := 0
+//    for i, n := 0, tree.IterInit(InOrder); n != nil; i, n = i+1, tree.Next() {
+//        elem := n.(exInt)  // (exInt is simple int type)
+//        sum += int(elem) + i
+//    }
+// Note: If one was to break out of the loop prior to a complete traversal,
+// and start another loop without calling IterInit, then the previously uncompleted iterator is continued again.
+func (t *SplayTree) Next() Interface {
+	next, _ := t.SplayTreeOf.Next()
+	return next
+}
+
+// IterInit is the initializer which setups the tree for iterating over it's elements in
+// a specific order. It setups the internal data, and then returns the first Interface to be looked at. See Next for an example.
+func (t *SplayTree) IterInit(order TravOrder) Interface {
+	first, _ := t.SplayTreeOf.IterInit(order)
+	return first
+}
+
+// Map is a more performance orientated way to iterate over the elements of the tree.
+// Given a TravOrder and a function which conforms to the IterFunc type:
+//
+//      type IterFunc func(Interface)
+//
+// Map calls the function for each Interface type in the specified order.
+func (t *SplayTree) Map(order TravOrder, f IterFunc) {
+	t.SplayTreeOf.Map(order, f)
+}
+
+// IterRangeInit is like IterInit, but only the elements between lo and hi are visited. Bounds
+// chooses whether lo and hi themselves are included. Only InOrder and RevOrder are possible, with
+// RevOrder starting from hi. See Next for an example.
+func (t *SplayTree) IterRangeInit(order TravOrder, lo, hi Interface, bounds Bounds) Interface {
+	if lo == nil || hi == nil {
+		t.iterNext = nil
+		return nil
+	}
+	first, _ := t.SplayTreeOf.IterRangeInit(order, lo, hi, bounds)
+	return first
+}
+
+// MapRange is like Map, but only the elements between lo and hi are visited. Bounds
+// chooses whether lo and hi themselves are included. Only InOrder and RevOrder are possible.
+func (t *SplayTree) MapRange(order TravOrder, lo, hi Interface, bounds Bounds, f IterFunc) {
+	if lo == nil || hi == nil {
+		return
+	}
+	t.SplayTreeOf.MapRange(order, lo, hi, bounds, f)
+}
+
+// Cursor returns a new unpositioned cursor over the tree. See CursorOf for an example.
+func (t *SplayTree) Cursor() Cursor {
+	return cursor{t.SplayTreeOf.Cursor()}
+}
+
+// Search returns the matching item if found, otherwise nil is returned.
+func (t *SplayTree) Search(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.SplayTreeOf.Search(item)
+	return
+}
+
+// Insert will either insert a new entry into the tree, and return nil. Or if there was a previous entry already inserted, then in addition to inserting the new item, the previously inserted item will be returned.
+func (t *SplayTree) Insert(item Interface) (old Interface) {
+	if item == nil {
+		return
+	}
+	old, _ = t.SplayTreeOf.Insert(item)
+	return
+}
+
+// Remove looks for a matching entry, and if found, the item is removed from the tree and old is populated with the removed item. If the item is not matched in the tree, nil is returned.
+func (t *SplayTree) Remove(item Interface) (old Interface) {
+	if item == nil {
+		return
+	}
+	old, _ = t.SplayTreeOf.Remove(item)
+	return
+}
+
+// Floor returns the largest element less than or equal to item, if there is none Floor returns a nil.
+func (t *SplayTree) Floor(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.SplayTreeOf.Floor(item)
+	return
+}
+
+// Ceiling returns the smallest element greater than or equal to item, if there is none Ceiling returns a nil.
+func (t *SplayTree) Ceiling(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.SplayTreeOf.Ceiling(item)
+	return
+}
+
+// Predecessor returns the largest element strictly less than item, if there is none Predecessor returns a nil.
+func (t *SplayTree) Predecessor(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.SplayTreeOf.Predecessor(item)
+	return
+}
+
+// Successor returns the smallest element strictly greater than item, if there is none Successor returns a nil.
+func (t *SplayTree) Successor(item Interface) (found Interface) {
+	if item == nil {
+		return
+	}
+	found, _ = t.SplayTreeOf.Successor(item)
+	return
+}
+
 // Size returns the number of elements currently inserted in the tree.
-func (t *SplayTree) Size() int {
+func (t *SplayTreeOf[T]) Size() int {
 	return t.size
 }
 
 // Height returns the max depth of any branch of the tree.
 // Note: Runs in O(n) where n is the maximum depthed branch.
-func (t *SplayTree) Height() int {
-	var calc func(n *SplayNode) int
-	calc = func(n *SplayNode) int {
+func (t *SplayTreeOf[T]) Height() int {
+	var calc func(n *SplayNodeOf[T]) int
+	calc = func(n *SplayNodeOf[T]) int {
 		if n == nil {
 			return 0
 		}
@@ -281,33 +936,33 @@ func (t *SplayTree) Height() int {
 	return calc(t.root)
 }
 
-func (h *SplayNode) min() *SplayNode {
+func (h *SplayNodeOf[T]) min() *SplayNodeOf[T] {
 	for ; h.left != nil; h = h.left {
 	}
 	return h
 }
-func (h *SplayNode) max() *SplayNode {
+func (h *SplayNodeOf[T]) max() *SplayNodeOf[T] {
 	for ; h.right != nil; h = h.right {
 	}
 	return h
 }
 
-func (t *SplayNode) splay(item Interface) (out *SplayNode) {
-	var left, right, parent *SplayNode
-	var n SplayNode
+func (tree *SplayTreeOf[T]) splay(t *SplayNodeOf[T], item T) (out *SplayNodeOf[T]) {
+	var left, right, parent *SplayNodeOf[T]
+	var n SplayNodeOf[T]
 	left = &n
 	right = &n
 
 L:
 	for {
-		switch t.Elem.Compare(item) {
+		switch tree.compare(t.Elem, item) {
 		//TODO NP case
 		case GT:
 			//fmt.Println("Madit LEft")
 			if t.left == nil {
 				break L
 			}
-			switch t.left.Elem.Compare(item) {
+			switch tree.compare(t.left.Elem, item) {
 			//TODO NP case
 			case GT:
 				// rotate right
@@ -328,7 +983,7 @@ L:
 				//fmt.Println("Madit Right")
 				break L
 			}
-			switch t.right.Elem.Compare(item) {
+			switch tree.compare(t.right.Elem, item) {
 			case LT:
 				// rotate left
 				parent = t.right